@@ -14,6 +14,7 @@ require (
 	github.com/pressly/goose/v3 v3.11.2
 	github.com/sirupsen/logrus v1.9.2
 	github.com/stretchr/testify v1.8.3
+	github.com/teambition/rrule-go v1.8.2
 	gorm.io/driver/postgres v1.5.2
 	gorm.io/gorm v1.25.1
 )
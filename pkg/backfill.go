@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+)
+
+const (
+	defaultBackfillInterval     = 1 * time.Second
+	defaultBackfillMaxInstances = 10000
+)
+
+type BackfillOptions struct {
+	// Interval spaces out the execution of the backfilled task instances so they don't starve live task instances,
+	// defaults to 1 second
+	Interval time.Duration
+	// MaxInstances caps the number of task instances a backfill creates, defaults to 10000
+	MaxInstances int
+}
+
+// Backfill() creates a task instance for every fire time of a recurring task definition between from and to,
+// inclusive. Fire times that already have a task instance are skipped. The task instances execute one per interval
+// starting now, and are marked as backfilled. The task definition's next fire time is left alone.
+func (s *Scheduler) Backfill(definitionId *uuid.UUID, from, to time.Time, opts BackfillOptions) ([]TaskInstance, error) {
+	if definitionId == nil {
+		return nil, errorx.IllegalArgument.New("an id must be provided")
+	}
+	if to.Before(from) {
+		return nil, errorx.IllegalArgument.New("from must be before to")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = defaultBackfillInterval
+	}
+	if opts.MaxInstances <= 0 {
+		opts.MaxInstances = defaultBackfillMaxInstances
+	}
+	taskDefinition, err := s.store.GetTaskDefinition(definitionId)
+	if err != nil {
+		return nil, err
+	}
+	if !taskDefinition.Recurring {
+		return nil, errorx.IllegalArgument.New("only recurring task definitions can be backfilled")
+	}
+	existingTaskInstances, err := s.store.ListTaskInstancesForTaskDefinition(definitionId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	existingFireTimes := map[time.Time]bool{}
+	for _, existingTaskInstance := range existingTaskInstances {
+		if existingTaskInstance.FireTime != nil {
+			existingFireTimes[normalizeFireTime(*existingTaskInstance.FireTime)] = true
+		}
+	}
+	taskDefinition = s.withCalendar(taskDefinition)
+	fireTimes := []time.Time{}
+	// triggers return the first fire time after the given time, step back so that from itself is included
+	fireTime := taskDefinition.getFireTimeWithinBounds(from.Add(-time.Nanosecond))
+	for fireTime != nil && !fireTime.After(to) && len(fireTimes) < opts.MaxInstances {
+		if !existingFireTimes[normalizeFireTime(*fireTime)] {
+			fireTimes = append(fireTimes, *fireTime)
+		}
+		fireTime = taskDefinition.getFireTimeWithinBounds(*fireTime)
+	}
+	taskInstances := []TaskInstance{}
+	executeAt := time.Now()
+	for _, fireTime := range fireTimes {
+		taskInstance, err := s.createBackfillTaskInstance(taskDefinition, fireTime, executeAt)
+		if err != nil {
+			return taskInstances, err
+		}
+		taskInstances = append(taskInstances, taskInstance)
+		executeAt = executeAt.Add(opts.Interval)
+	}
+	return taskInstances, nil
+}
+
+func (s *Scheduler) createBackfillTaskInstance(taskDefinition TaskDefinition, fireTime, executeAt time.Time) (TaskInstance, error) {
+	id := uuid.New()
+	expiresAt := executeAt.Add(taskDefinition.ExpireAfter)
+	taskInstance := TaskInstance{
+		Id:             &id,
+		ExpiresAt:      &expiresAt,
+		ExecuteAt:      &executeAt,
+		FireTime:       &fireTime,
+		Backfill:       true,
+		TenantId:       taskDefinition.TenantId,
+		TaskDefinition: taskDefinition,
+	}
+	return taskInstance, s.store.UpsertTaskInstance(taskInstance)
+}
+
+// normalizeFireTime() drops the precision that stores may not keep, so fire times can be compared after a round trip
+func normalizeFireTime(fireTime time.Time) time.Time {
+	return fireTime.UTC().Truncate(time.Microsecond)
+}
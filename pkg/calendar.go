@@ -0,0 +1,258 @@
+package pkg
+
+import (
+	"time"
+
+	"github.com/catalystsquad/app-utils-go/logging"
+	"github.com/joomcode/errorx"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	calendarDateFormat = "2006-01-02"
+	calendarTimeFormat = "15:04"
+	// maxCalendarSkips caps how many excluded periods a fire time skips before the trigger is considered to never fire
+	maxCalendarSkips = 1000
+)
+
+// Calendar is a named set of excluded periods. Task definitions that reference a calendar don't fire during its
+// excluded periods, their CalendarPolicy decides whether those fire times are skipped or deferred.
+type Calendar struct {
+	Name string `json:"name"`
+	// Location is the IANA time zone name dates and weekly windows are evaluated in, UTC if empty
+	Location string `json:"location"`
+	// Dates are excluded days, like holidays, formatted as 2006-01-02
+	Dates []string `json:"dates"`
+	// WeeklyWindows are excluded every week, like a maintenance window
+	WeeklyWindows []WeeklyWindow `json:"weekly_windows"`
+	// Ranges are arbitrary excluded periods
+	Ranges []TimeRange `json:"ranges"`
+}
+
+// WeeklyWindow is excluded for Duration from Start, formatted as 15:04, on Weekday
+type WeeklyWindow struct {
+	Weekday  time.Weekday  `json:"weekday"`
+	Start    string        `json:"start"`
+	Duration time.Duration `json:"duration"`
+}
+
+// TimeRange is excluded from Start, inclusive, until End
+type TimeRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// CalendarPolicy controls what happens to fire times that land in an excluded period of a task definition's calendar
+type CalendarPolicy string
+
+const (
+	// CalendarPolicySkip drops fire times in excluded periods and fires at the trigger's next fire time after them, this
+	// is the default
+	CalendarPolicySkip CalendarPolicy = "skip"
+	// CalendarPolicyDefer fires once when the excluded period ends
+	CalendarPolicyDefer CalendarPolicy = "defer"
+)
+
+func (p CalendarPolicy) IsValid() bool {
+	switch p {
+	case "", CalendarPolicySkip, CalendarPolicyDefer:
+		return true
+	}
+	return false
+}
+
+func (c Calendar) Validate() error {
+	if c.Name == "" {
+		return errorx.IllegalArgument.New("calendars must have a name")
+	}
+	if _, err := time.LoadLocation(c.Location); err != nil {
+		return errorx.IllegalArgument.Wrap(err, "invalid calendar location: %s", c.Location)
+	}
+	for _, date := range c.Dates {
+		if _, err := time.Parse(calendarDateFormat, date); err != nil {
+			return errorx.IllegalArgument.New("invalid calendar date, expected 2006-01-02: %s", date)
+		}
+	}
+	for _, window := range c.WeeklyWindows {
+		if _, err := time.Parse(calendarTimeFormat, window.Start); err != nil {
+			return errorx.IllegalArgument.New("invalid weekly window start, expected 15:04: %s", window.Start)
+		}
+		if window.Duration <= 0 || window.Duration > 7*24*time.Hour {
+			return errorx.IllegalArgument.New("weekly window duration must be positive and at most a week")
+		}
+	}
+	for _, timeRange := range c.Ranges {
+		if !timeRange.End.After(timeRange.Start) {
+			return errorx.IllegalArgument.New("calendar range end must be after its start")
+		}
+	}
+	return nil
+}
+
+// Excludes() returns true if t is in an excluded period
+func (c Calendar) Excludes(t time.Time) bool {
+	return c.periodEnd(t) != nil
+}
+
+// excludedUntil() returns the end of the excluded period containing t, or nil if t isn't excluded. Adjacent and
+// overlapping periods count as a single period.
+func (c Calendar) excludedUntil(t time.Time) *time.Time {
+	var until *time.Time
+	for i := 0; i < maxCalendarSkips; i++ {
+		end := c.periodEnd(t)
+		if end == nil {
+			break
+		}
+		until = end
+		t = *end
+	}
+	return until
+}
+
+// periodEnd() returns the latest end of the excluded periods containing t, or nil if t isn't excluded
+func (c Calendar) periodEnd(t time.Time) *time.Time {
+	location := c.getLocation()
+	day := dateOf(t.In(location))
+	var latest *time.Time
+	include := func(start, end time.Time) {
+		if !t.Before(start) && t.Before(end) && (latest == nil || end.After(*latest)) {
+			latest = &end
+		}
+	}
+	for _, date := range c.Dates {
+		if date == day.Format(calendarDateFormat) {
+			include(fromWallClock(day, location), fromWallClock(day.AddDate(0, 0, 1), location))
+		}
+	}
+	for _, window := range c.WeeklyWindows {
+		start, err := time.Parse(calendarTimeFormat, window.Start)
+		if err != nil {
+			continue
+		}
+		timeOfDay := time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute
+		// windows last at most a week, so one that contains t started within the last 7 days
+		for daysAgo := 0; daysAgo <= 7; daysAgo++ {
+			windowDay := day.AddDate(0, 0, -daysAgo)
+			if windowDay.Weekday() == window.Weekday {
+				windowStart := fromWallClock(windowDay.Add(timeOfDay), location)
+				include(windowStart, windowStart.Add(window.Duration))
+			}
+		}
+	}
+	for _, timeRange := range c.Ranges {
+		include(timeRange.Start, timeRange.End)
+	}
+	return latest
+}
+
+func (c Calendar) getLocation() *time.Location {
+	if c.Location != "" {
+		location, err := time.LoadLocation(c.Location)
+		if err == nil {
+			return location
+		}
+	}
+	return time.UTC
+}
+
+// applyCalendar() moves a fire time out of the task definition's calendar's excluded periods according to its
+// calendar policy. Non recurring triggers have no later fire time to skip to, so they're always deferred. Returns nil
+// if every fire time is excluded.
+func (t TaskDefinition) applyCalendar(fireTime *time.Time) *time.Time {
+	if t.calendar == nil {
+		return fireTime
+	}
+	trigger := t.GetTrigger()
+	for i := 0; fireTime != nil && i < maxCalendarSkips; i++ {
+		until := t.calendar.excludedUntil(*fireTime)
+		if until == nil {
+			return fireTime
+		}
+		if t.CalendarPolicy == CalendarPolicyDefer || !trigger.IsRecurring() {
+			return until
+		}
+		// the trigger's first fire time at or after the end of the excluded period
+		fireTime = t.getJitteredFireTime(until.Add(-time.Nanosecond))
+	}
+	return nil
+}
+
+// WithCalendar() returns a copy of the task definition evaluated against the calendar, the scheduler does this for
+// task definitions with a CalendarName
+func (t TaskDefinition) WithCalendar(calendar *Calendar) TaskDefinition {
+	t.calendar = calendar
+	return t
+}
+
+func (s *Scheduler) UpsertCalendar(calendar Calendar) error {
+	err := calendar.Validate()
+	if err != nil {
+		return err
+	}
+	err = s.store.UpsertCalendar(calendar)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	delete(s.calendars, calendar.Name)
+	s.lock.Unlock()
+	return nil
+}
+
+func (s *Scheduler) GetCalendar(name string) (Calendar, error) {
+	return s.store.GetCalendar(name)
+}
+
+func (s *Scheduler) ListCalendars() ([]Calendar, error) {
+	return s.store.ListCalendars()
+}
+
+// DeleteCalendar() deletes the calendar, task definitions that reference it fire without exclusions
+func (s *Scheduler) DeleteCalendar(name string) error {
+	err := s.store.DeleteCalendar(name)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	delete(s.calendars, name)
+	s.lock.Unlock()
+	return nil
+}
+
+type cachedCalendar struct {
+	calendar  Calendar
+	expiresAt time.Time
+}
+
+// getCachedCalendar() returns the named calendar, calendars are cached for a schedule window so that every task
+// definition that references one doesn't fetch it again
+func (s *Scheduler) getCachedCalendar(name string) (*Calendar, error) {
+	s.lock.Lock()
+	cached, ok := s.calendars[name]
+	s.lock.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return &cached.calendar, nil
+	}
+	calendar, err := s.store.GetCalendar(name)
+	if err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	s.calendars[name] = cachedCalendar{calendar: calendar, expiresAt: time.Now().Add(*s.ScheduleWindow)}
+	s.lock.Unlock()
+	return &calendar, nil
+}
+
+// withCalendar() attaches the task definition's calendar so its fire times respect the calendar's exclusions. A
+// calendar that can't be fetched is logged, and the task definition fires without exclusions.
+func (s *Scheduler) withCalendar(taskDefinition TaskDefinition) TaskDefinition {
+	if taskDefinition.CalendarName == "" {
+		return taskDefinition
+	}
+	calendar, err := s.getCachedCalendar(taskDefinition.CalendarName)
+	if err != nil {
+		logging.Log.WithError(err).WithFields(logrus.Fields{"task_definition_id": taskDefinition.Id, "calendar": taskDefinition.CalendarName}).Error("error getting calendar")
+		return taskDefinition
+	}
+	return taskDefinition.WithCalendar(calendar)
+}
@@ -0,0 +1,108 @@
+package cockroachdb_store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/catalystsquad/app-utils-go/logging"
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/catalystsquad/go-scheduler/pkg/cockroachdb_store/models"
+	"github.com/cockroachdb/cockroach-go/v2/crdb/crdbgorm"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// notifications only need to live long enough for every listening node to receive them
+const notificationRetention = 1 * time.Minute
+
+// ChangefeedNotifier notifies nodes by writing to the scheduler_notifications table and listens with a core
+// changefeed on that table. Changefeeds require rangefeeds, enable them with
+// `SET CLUSTER SETTING kv.rangefeed.enabled = true`. The table is created by the CockroachdbStore migrations, so the
+// store must be initialized before the notifier is used.
+type ChangefeedNotifier struct {
+	db     *gorm.DB
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewChangefeedNotifier(uri string, config *gorm.Config) (pkg.NotifierInterface, error) {
+	if config == nil {
+		config = &gorm.Config{}
+	}
+	db, err := gorm.Open(postgres.Open(uri), config)
+	if err != nil {
+		logging.Log.WithError(err).Error("error connecting to cockroachdb")
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ChangefeedNotifier{db: db, ctx: ctx, cancel: cancel}, nil
+}
+
+// changefeedRow is the wrapped envelope of a changefeed row, only the fields we need are unmarshalled
+type changefeedRow struct {
+	After *struct {
+		TaskDefinitionId *uuid.UUID `json:"task_definition_id"`
+	} `json:"after"`
+}
+
+func (n *ChangefeedNotifier) Notify(definition pkg.TaskDefinition) error {
+	err := crdbgorm.ExecuteTx(context.Background(), n.db, nil, func(tx *gorm.DB) error {
+		err := tx.Create(&models.Notification{TaskDefinitionId: definition.Id}).Error
+		if err != nil {
+			return err
+		}
+		// clean up old notifications as we go, deletes show up in the changefeed without an after value and are skipped
+		return tx.Where("created_at < ?", time.Now().Add(-notificationRetention).UnixNano()).Delete(&models.Notification{}).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error notifying task definition")
+	}
+	return err
+}
+
+func (n *ChangefeedNotifier) Listen(callback func(definitionId *uuid.UUID)) error {
+	sqldb, err := n.db.DB()
+	if err != nil {
+		return err
+	}
+	// core changefeeds stream rows for as long as the query runs, it's cancelled by Close()
+	rows, err := sqldb.QueryContext(n.ctx, "experimental changefeed for scheduler_notifications")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table string
+		var key, value []byte
+		err = rows.Scan(&table, &key, &value)
+		if err != nil {
+			return err
+		}
+		row := changefeedRow{}
+		err = json.Unmarshal(value, &row)
+		if err != nil {
+			logging.Log.WithError(err).Error("error unmarshalling changefeed row")
+			continue
+		}
+		if row.After == nil || row.After.TaskDefinitionId == nil {
+			continue
+		}
+		callback(row.After.TaskDefinitionId)
+	}
+	if n.ctx.Err() != nil {
+		// closed
+		return nil
+	}
+	return rows.Err()
+}
+
+func (n *ChangefeedNotifier) Close() error {
+	n.cancel()
+	sqldb, err := n.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqldb.Close()
+}
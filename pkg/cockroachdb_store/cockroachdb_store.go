@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/catalystsquad/app-utils-go/logging"
@@ -11,6 +13,7 @@ import (
 	"github.com/catalystsquad/go-scheduler/pkg/cockroachdb_store/models"
 	"github.com/cockroachdb/cockroach-go/v2/crdb/crdbgorm"
 	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
 	"github.com/pressly/goose/v3"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -134,12 +137,16 @@ func (c *CockroachdbStore) DeleteTaskDefinitions(ids []*uuid.UUID) error {
 	})
 }
 
-func (c *CockroachdbStore) GetTaskDefinitionsToSchedule(limit time.Time) ([]pkg.TaskDefinition, error) {
+func (c *CockroachdbStore) GetTaskDefinitionsToSchedule(limit time.Time, shards []int) ([]pkg.TaskDefinition, error) {
 	limit = limit.UTC()
 	taskDefinitionModels := []models.TaskDefinition{}
 	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
 		// query for task definitions that aren't completed, whose next fire time is less than the limit
-		return tx.Preload(clause.Associations).Where("completed_at is null and next_fire_time is not null and next_fire_time <= ?", limit).Find(&taskDefinitionModels).Error
+		tx = tx.Preload(clause.Associations).Where("completed_at is null and paused = false and next_fire_time is not null and next_fire_time <= ?", limit)
+		if shards != nil {
+			tx = tx.Where("shard in ?", shards)
+		}
+		return tx.Find(&taskDefinitionModels).Error
 	})
 	if err != nil {
 		return nil, err
@@ -156,25 +163,79 @@ func (c *CockroachdbStore) GetTaskDefinitionsToSchedule(limit time.Time) ([]pkg.
 }
 
 func (c *CockroachdbStore) MarkTaskInstanceComplete(taskInstance pkg.TaskInstance) error {
+	return c.markTaskInstanceDone(taskInstance, map[string]interface{}{}, nil)
+}
+
+func (c *CockroachdbStore) MarkTaskInstanceFailed(taskInstance pkg.TaskInstance) error {
+	return c.markTaskInstanceDone(taskInstance, map[string]interface{}{"failed_at": time.Now().UTC()}, nil)
+}
+
+func (c *CockroachdbStore) MarkTaskInstanceDoneAndReschedule(taskInstance pkg.TaskInstance, failed bool, nextFireTime *time.Time) error {
+	updates := map[string]interface{}{}
+	if failed {
+		updates["failed_at"] = time.Now().UTC()
+	}
+	return c.markTaskInstanceDone(taskInstance, updates, rescheduleUpdates(nextFireTime))
+}
+
+// markTaskInstanceDone() completes the task instance with any additional updates, and applies the definition updates
+// to its task definition afterwards if they're non nil
+func (c *CockroachdbStore) markTaskInstanceDone(taskInstance pkg.TaskInstance, updates, definitionUpdates map[string]interface{}) error {
 	completedAt := time.Now().UTC()
+	updates["completed_at"] = completedAt
 	return crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
-		// if the parent task definition is not recurring, this marks it as completed in a single query
-		err := tx.Model(&models.TaskDefinition{}).Where("id = ? and recurring = false", taskInstance.TaskDefinition.Id).Update("completed_at", completedAt).Error
+		// if the parent task definition is not recurring, this marks it as completed in a single query. Manually triggered
+		// task instances don't complete their task definition.
+		if !taskInstance.Manual {
+			err := tx.Model(&models.TaskDefinition{}).Where("id = ? and recurring = false", taskInstance.TaskDefinition.Id).Updates(map[string]interface{}{"completed_at": completedAt, "version": gorm.Expr("version + 1")}).Error
+			if err != nil {
+				logging.Log.WithError(err).Error("error marking task definition complete")
+				return err
+			}
+		}
+		err := tx.Omit("TaskDefinition").Model(&models.TaskInstance{}).Where("id = ?", taskInstance.Id).Updates(updates).Error
 		if err != nil {
-			logging.Log.WithError(err).Error("error marking task definition complete")
+			logging.Log.WithError(err).Error("error marking task instance complete")
 			return err
 		}
-		err = tx.Omit("TaskDefinition").Model(&models.TaskInstance{}).Where("id = ?", taskInstance.Id).Update("completed_at", completedAt).Error
+		if definitionUpdates == nil {
+			return nil
+		}
+		// rescheduling in the same transaction undoes completing a non recurring task definition above, so cleanup
+		// can't delete it in between
+		err = tx.Model(&models.TaskDefinition{}).Where("id = ?", taskInstance.TaskDefinition.Id).Updates(definitionUpdates).Error
 		if err != nil {
-			logging.Log.WithError(err).Error("error marking task instance complete")
+			logging.Log.WithError(err).Error("error rescheduling task definition")
 		}
 		return err
 	})
 }
 
+func (c *CockroachdbStore) RescheduleTaskDefinition(id *uuid.UUID, nextFireTime *time.Time) error {
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Model(&models.TaskDefinition{}).Where("id = ?", id).Updates(rescheduleUpdates(nextFireTime)).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error rescheduling task definition")
+	}
+	return err
+}
+
+// rescheduleUpdates() returns the updates that set a task definition's next fire time, a task definition with no next
+// fire time is complete
+func rescheduleUpdates(nextFireTime *time.Time) map[string]interface{} {
+	var completedAt *time.Time
+	if nextFireTime == nil {
+		now := time.Now().UTC()
+		completedAt = &now
+	}
+	return map[string]interface{}{"next_fire_time": utcTime(nextFireTime), "completed_at": completedAt, "version": gorm.Expr("version + 1")}
+}
+
 func (c *CockroachdbStore) DeleteCompletedTaskInstances() error {
 	return crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
-		err := tx.Where("completed_at is not null").Delete(&models.TaskInstance{}).Error
+		// task instances of workflow runs that haven't completed are kept, the run's remaining task instances depend on them
+		err := tx.Where("completed_at is not null and (workflow_run_id is null or workflow_run_id not in (select id from workflow_runs where completed_at is null))").Delete(&models.TaskInstance{}).Error
 		if err != nil {
 			logging.Log.WithError(err).Error("error deleting completed task instances")
 		}
@@ -184,7 +245,9 @@ func (c *CockroachdbStore) DeleteCompletedTaskInstances() error {
 
 func (c *CockroachdbStore) DeleteCompletedTaskDefinitions() error {
 	return crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
-		err := tx.Where("completed_at is not null").Delete(&models.TaskDefinition{}).Error
+		// recurring task definitions are completed when their bounds are exhausted, which can be before their last task
+		// instances complete, and root task definitions complete before the workflow runs they started
+		err := tx.Where("completed_at is not null and not exists (select 1 from task_instances where task_instances.task_definition_id = task_definitions.id and task_instances.completed_at is null) and not exists (select 1 from workflow_runs where workflow_runs.root_task_definition_id = task_definitions.id and workflow_runs.completed_at is null)").Delete(&models.TaskDefinition{}).Error
 		if err != nil {
 			logging.Log.WithError(err).Error("error deleting completed task definitions")
 		}
@@ -237,6 +300,18 @@ func (c *CockroachdbStore) ListTaskInstances(offset, limit int) ([]pkg.TaskInsta
 	return models.ToTaskInstances(taskInstanceModels)
 }
 
+func (c *CockroachdbStore) ListTaskInstancesForTaskDefinition(definitionId *uuid.UUID, from, to time.Time) ([]pkg.TaskInstance, error) {
+	taskInstanceModels := []models.TaskInstance{}
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Preload(clause.Associations).Where("task_definition_id = ? and fire_time >= ? and fire_time <= ?", definitionId, from.UTC(), to.UTC()).Order("fire_time").Find(&taskInstanceModels).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error listing task instances for task definition")
+		return nil, err
+	}
+	return models.ToTaskInstances(taskInstanceModels)
+}
+
 func (c *CockroachdbStore) ListTaskDefinitions(offset, limit int, metadataQuery interface{}) ([]pkg.TaskDefinition, error) {
 	taskDefinitionModels := []models.TaskDefinition{}
 	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
@@ -274,12 +349,18 @@ func (c *CockroachdbStore) DeleteTaskDefinition(id *uuid.UUID) error {
 	return err
 }
 
-func (c *CockroachdbStore) GetTaskInstancesToRun(limit time.Time) ([]pkg.TaskInstance, error) {
+func (c *CockroachdbStore) GetTaskInstancesToRun(limit time.Time, shards []int) ([]pkg.TaskInstance, error) {
 	limit = limit.UTC()
 	taskInstanceModels := []models.TaskInstance{}
 	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
 		// query for task instances that aren't completed, and either aren't in progress, or are in progress but have expired
-		return tx.Preload(clause.Associations).Where("completed_at is null and ((started_at is null and execute_at <= ?) or (started_at is not null and expires_at <= now()))", limit).Find(&taskInstanceModels).Error
+		tx = tx.Preload(clause.Associations).Where("completed_at is null and ((started_at is null and execute_at <= ?) or (started_at is not null and expires_at <= now()))", limit)
+		// task instances of paused task definitions wait until they're resumed, unless they were triggered manually
+		tx = tx.Where("manual or task_definition_id not in (select id from task_definitions where paused)")
+		if shards != nil {
+			tx = tx.Where("task_definition_id in (select id from task_definitions where shard in ?)", shards)
+		}
+		return tx.Find(&taskInstanceModels).Error
 	})
 	if err != nil {
 		return nil, err
@@ -330,12 +411,556 @@ func (c *CockroachdbStore) UpsertTaskDefinition(taskDefinition pkg.TaskDefinitio
 		return err
 	}
 	err = crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
-		return tx.Omit("TaskInstances").Clauses(clause.OnConflict{
+		// the version is checked and incremented in the same transaction as the upsert
+		versions := []int64{}
+		err := tx.Model(&models.TaskDefinition{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", taskDefinition.Id).Pluck("version", &versions).Error
+		if err != nil {
+			return err
+		}
+		if taskDefinition.Version > 0 && (len(versions) == 0 || versions[0] != taskDefinition.Version) {
+			return pkg.VersionConflict.New("task definition %s has changed since version %d", taskDefinition.Id, taskDefinition.Version)
+		}
+		taskDefinitionModel.Version = 1
+		if len(versions) > 0 {
+			taskDefinitionModel.Version = versions[0] + 1
+		}
+		// paused is only changed by pausing and resuming, and a task definition can't be moved to another tenant
+		result := tx.Omit("TaskInstances", "Paused", "PausedAt").Clauses(clause.OnConflict{
 			UpdateAll: true,
-		}).Create(&taskDefinitionModel).Error
+			Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "task_definitions.tenant_id = excluded.tenant_id"}}},
+		}).Create(&taskDefinitionModel)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// the conflict update was skipped, rolling back any triggers saved with it
+			return pkg.TenantMismatch.New("task definition %s belongs to another tenant", taskDefinition.Id)
+		}
+		return nil
 	})
-	if err != nil {
+	if c.isDuplicateKey(err) {
+		return pkg.DuplicateKey.Wrap(err, "task definition with key %s already exists", taskDefinition.Key)
+	}
+	if err != nil && !errorx.IsOfType(err, pkg.VersionConflict) && !errorx.IsOfType(err, pkg.TenantMismatch) {
 		logging.Log.WithError(err).Error("error upserting task with cockroachdb store")
 	}
 	return err
 }
+
+func (c *CockroachdbStore) ScheduleTaskDefinition(taskDefinition pkg.TaskDefinition, taskInstances []pkg.TaskInstance) ([]pkg.TaskInstance, error) {
+	taskInstanceModels := []*models.TaskInstance{}
+	for _, taskInstance := range taskInstances {
+		taskInstanceModel, err := models.GetTaskInstanceModelFromTaskInstance(taskInstance)
+		if err != nil {
+			return nil, err
+		}
+		taskInstanceModels = append(taskInstanceModels, taskInstanceModel)
+	}
+	updates := map[string]interface{}{
+		"next_fire_time": utcTime(taskDefinition.NextFireTime),
+		"completed_at":   utcTime(taskDefinition.CompletedAt),
+		"occurrences":    taskDefinition.Occurrences,
+		"version":        gorm.Expr("version + 1"),
+	}
+	created := []pkg.TaskInstance{}
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		// the transaction can be retried, only report the task instances created by the attempt that commits
+		created = []pkg.TaskInstance{}
+		result := tx.Model(&models.TaskDefinition{}).Where("id = ? and version = ?", taskDefinition.Id, taskDefinition.Version).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return pkg.VersionConflict.New("task definition %s has changed since version %d", taskDefinition.Id, taskDefinition.Version)
+		}
+		for i, taskInstanceModel := range taskInstanceModels {
+			// task instances are unique per fire time, one that was already created for the fire time is left alone
+			result = tx.Omit("TaskDefinition").Clauses(clause.OnConflict{DoNothing: true}).Create(taskInstanceModel)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				created = append(created, taskInstances[i])
+			}
+		}
+		return nil
+	})
+	if err != nil && !errorx.IsOfType(err, pkg.VersionConflict) {
+		logging.Log.WithError(err).Error("error scheduling task definition")
+	}
+	return created, err
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// isDuplicateKey() returns true if the error is a unique constraint violation, whether or not the gorm config
+// translates errors
+func (c *CockroachdbStore) isDuplicateKey(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	translator, ok := c.db.Dialector.(gorm.ErrorTranslator)
+	for ; err != nil && ok; err = errors.Unwrap(err) {
+		if errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CockroachdbStore) AcquireLease(name string, holderId *uuid.UUID, ttl time.Duration) (bool, error) {
+	acquired := false
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		expiresAt := now.Add(ttl)
+		lease := models.Lease{Name: name, HolderId: holderId, ExpiresAt: &expiresAt}
+		// only take over an existing lease if it's already ours, or if it has expired
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"holder_id", "expires_at", "updated_at"}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Expr{SQL: "scheduler_leases.holder_id = excluded.holder_id or scheduler_leases.expires_at < ?", Vars: []interface{}{now}},
+			}},
+		}).Create(&lease)
+		acquired = result.RowsAffected > 0
+		return result.Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error acquiring lease")
+		return false, err
+	}
+	return acquired, nil
+}
+
+func (c *CockroachdbStore) ReleaseLease(name string, holderId *uuid.UUID) error {
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Where("name = ? and holder_id = ?", name, holderId).Delete(&models.Lease{}).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error releasing lease")
+	}
+	return err
+}
+
+func (c *CockroachdbStore) UpsertNode(node pkg.Node) error {
+	nodeModel, err := models.GetNodeModelFromNode(node)
+	if err != nil {
+		return err
+	}
+	err = crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			UpdateAll: true,
+		}).Create(&nodeModel).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error upserting node")
+	}
+	return err
+}
+
+func (c *CockroachdbStore) ListNodes() ([]pkg.Node, error) {
+	nodeModels := []models.Node{}
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Order("started_at").Find(&nodeModels).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error listing nodes")
+		return nil, err
+	}
+	return models.ToNodes(nodeModels)
+}
+
+func (c *CockroachdbStore) DeleteNode(id *uuid.UUID) error {
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Delete(models.Node{Id: id}).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error deleting node")
+	}
+	return err
+}
+
+func (c *CockroachdbStore) DeleteNodesNotSeenSince(limit time.Time) error {
+	limit = limit.UTC()
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Where("heartbeat_at < ?", limit).Delete(&models.Node{}).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error deleting nodes not seen since limit")
+	}
+	return err
+}
+
+func (c *CockroachdbStore) ListTaskInstancesHeldByDeadNodes(limit time.Time) ([]pkg.TaskInstance, error) {
+	limit = limit.UTC()
+	taskInstanceModels := []models.TaskInstance{}
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		// in progress task instances whose node is gone, or whose node hasn't sent a heartbeat since the limit
+		return tx.Preload(clause.Associations).Where("completed_at is null and started_at is not null and node_id is not null and node_id not in (select id from scheduler_nodes where heartbeat_at >= ?)", limit).Find(&taskInstanceModels).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error listing task instances held by dead nodes")
+		return nil, err
+	}
+	return models.ToTaskInstances(taskInstanceModels)
+}
+
+func (c *CockroachdbStore) UpsertCalendar(calendar pkg.Calendar) error {
+	calendarModel, err := models.GetCalendarModelFromCalendar(calendar)
+	if err != nil {
+		return err
+	}
+	err = crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			UpdateAll: true,
+		}).Create(&calendarModel).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error upserting calendar")
+	}
+	return err
+}
+
+func (c *CockroachdbStore) GetCalendar(name string) (pkg.Calendar, error) {
+	calendarModel := models.Calendar{}
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Where("name = ?", name).First(&calendarModel).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error getting calendar")
+		return pkg.Calendar{}, err
+	}
+	return calendarModel.ToCalendar()
+}
+
+func (c *CockroachdbStore) ListCalendars() ([]pkg.Calendar, error) {
+	calendarModels := []models.Calendar{}
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Order("name").Find(&calendarModels).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error listing calendars")
+		return nil, err
+	}
+	return models.ToCalendars(calendarModels)
+}
+
+func (c *CockroachdbStore) DeleteCalendar(name string) error {
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Where("name = ?", name).Delete(&models.Calendar{}).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error deleting calendar")
+	}
+	return err
+}
+
+func (c *CockroachdbStore) InsertTaskInstance(taskInstance pkg.TaskInstance) (bool, error) {
+	taskInstanceModel, err := models.GetTaskInstanceModelFromTaskInstance(taskInstance)
+	if err != nil {
+		return false, err
+	}
+	created := false
+	err = crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		result := tx.Omit("TaskDefinition").Clauses(clause.OnConflict{DoNothing: true}).Create(&taskInstanceModel)
+		created = result.RowsAffected > 0
+		return result.Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error inserting task instance")
+	}
+	return created, err
+}
+
+func (c *CockroachdbStore) ListDependentTaskDefinitions(id *uuid.UUID) ([]pkg.TaskDefinition, error) {
+	dependsOn, err := json.Marshal([]*uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	taskDefinitionModels := []models.TaskDefinition{}
+	err = crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Preload(clause.Associations).Where("depends_on @> ?::jsonb", string(dependsOn)).Find(&taskDefinitionModels).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error listing dependent task definitions")
+		return nil, err
+	}
+	return models.ToTaskDefinitions(taskDefinitionModels)
+}
+
+func (c *CockroachdbStore) StartWorkflowRun(workflowRun pkg.WorkflowRun, rootTaskInstanceId *uuid.UUID) error {
+	workflowRunModel, err := models.GetWorkflowRunModelFromWorkflowRun(workflowRun)
+	if err != nil {
+		return err
+	}
+	err = crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&workflowRunModel).Error
+		if err != nil {
+			return err
+		}
+		return tx.Omit("TaskDefinition").Model(&models.TaskInstance{}).Where("id = ?", rootTaskInstanceId).Update("workflow_run_id", workflowRun.Id).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error starting workflow run")
+	}
+	return err
+}
+
+func (c *CockroachdbStore) UpsertWorkflowRun(workflowRun pkg.WorkflowRun) error {
+	workflowRunModel, err := models.GetWorkflowRunModelFromWorkflowRun(workflowRun)
+	if err != nil {
+		return err
+	}
+	err = crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			UpdateAll: true,
+		}).Create(&workflowRunModel).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error upserting workflow run")
+	}
+	return err
+}
+
+func (c *CockroachdbStore) GetWorkflowRun(id *uuid.UUID) (pkg.WorkflowRun, error) {
+	workflowRunModel := models.WorkflowRun{Id: id}
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.First(&workflowRunModel).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error getting workflow run")
+		return pkg.WorkflowRun{}, err
+	}
+	return workflowRunModel.ToWorkflowRun()
+}
+
+func (c *CockroachdbStore) ListWorkflowRuns(rootTaskDefinitionId *uuid.UUID, offset, limit int) ([]pkg.WorkflowRun, error) {
+	workflowRunModels := []models.WorkflowRun{}
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Where("root_task_definition_id = ?", rootTaskDefinitionId).Order("started_at desc").Offset(offset).Limit(limit).Find(&workflowRunModels).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error listing workflow runs")
+		return nil, err
+	}
+	return models.ToWorkflowRuns(workflowRunModels)
+}
+
+func (c *CockroachdbStore) ListTaskInstancesForWorkflowRun(id *uuid.UUID) ([]pkg.TaskInstance, error) {
+	taskInstanceModels := []models.TaskInstance{}
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Preload(clause.Associations).Where("workflow_run_id = ?", id).Order("created_at").Find(&taskInstanceModels).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error listing task instances for workflow run")
+		return nil, err
+	}
+	return models.ToTaskInstances(taskInstanceModels)
+}
+
+func (c *CockroachdbStore) CountTaskInstancesInSlots(from time.Time, slot time.Duration, slots int) ([]int, error) {
+	from = from.UTC()
+	to := from.Add(time.Duration(slots) * slot)
+	slotCounts := []struct {
+		Slot  int
+		Count int
+	}{}
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		// the index of the slot each task instance executes in, counted from from
+		return tx.Model(&models.TaskInstance{}).
+			Select("floor((extract(epoch from execute_at)::float8 - ?) / ?)::int8 as slot, count(*) as count", float64(from.UnixNano())/float64(time.Second), slot.Seconds()).
+			Where("completed_at is null and execute_at >= ? and execute_at < ?", from, to).
+			Group("slot").
+			Scan(&slotCounts).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error counting task instances in slots")
+		return nil, err
+	}
+	counts := make([]int, slots)
+	for _, slotCount := range slotCounts {
+		if slotCount.Slot >= 0 && slotCount.Slot < slots {
+			counts[slotCount.Slot] = slotCount.Count
+		}
+	}
+	return counts, nil
+}
+
+func (c *CockroachdbStore) PauseTaskDefinition(id *uuid.UUID) error {
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Model(&models.TaskDefinition{}).Where("id = ? and paused = false", id).Updates(map[string]interface{}{"paused": true, "paused_at": time.Now().UTC(), "version": gorm.Expr("version + 1")}).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error pausing task definition")
+	}
+	return err
+}
+
+func (c *CockroachdbStore) ResumeTaskDefinition(id *uuid.UUID, nextFireTime *time.Time) error {
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"paused": false, "paused_at": nil, "version": gorm.Expr("version + 1")}
+		if nextFireTime != nil {
+			updates["next_fire_time"] = nextFireTime.UTC()
+			// task instances for the missed fire times
+			err := tx.Where("task_definition_id = ? and started_at is null and completed_at is null and not manual and execute_at < ?", id, time.Now().UTC()).Delete(&models.TaskInstance{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.TaskDefinition{}).Where("id = ?", id).Updates(updates).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error resuming task definition")
+	}
+	return err
+}
+
+func (c *CockroachdbStore) ListTaskDefinitionsInNamespace(namespace string, offset, limit int) ([]pkg.TaskDefinition, error) {
+	taskDefinitionModels := []models.TaskDefinition{}
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Preload(clause.Associations).Where("namespace = ?", namespace).Order("created_at").Offset(offset).Limit(limit).Find(&taskDefinitionModels).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error listing task definitions in namespace")
+		return nil, err
+	}
+	return models.ToTaskDefinitions(taskDefinitionModels)
+}
+
+func (c *CockroachdbStore) CountTaskDefinitionsInNamespace(namespace string) (int64, error) {
+	var count int64
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Model(&models.TaskDefinition{}).Where("namespace = ?", namespace).Count(&count).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error counting task definitions in namespace")
+	}
+	return count, err
+}
+
+func (c *CockroachdbStore) PauseTaskDefinitionsInNamespace(namespace string) error {
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Model(&models.TaskDefinition{}).Where("namespace = ? and paused = false", namespace).Updates(map[string]interface{}{"paused": true, "paused_at": time.Now().UTC(), "version": gorm.Expr("version + 1")}).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error pausing task definitions in namespace")
+	}
+	return err
+}
+
+func (c *CockroachdbStore) DeleteTaskDefinitionsInNamespace(namespace string) error {
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Where("namespace = ?", namespace).Delete(&models.TaskDefinition{}).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error deleting task definitions in namespace")
+	}
+	return err
+}
+
+// applyTaskDefinitionFilter() adds the filter's conditions to the query
+func applyTaskDefinitionFilter(tx *gorm.DB, filter pkg.TaskDefinitionFilter) (*gorm.DB, error) {
+	if filter.Key != "" {
+		tx = tx.Where("tenant_id = ? and key = ?", filter.TenantId, filter.Key)
+	} else if filter.TenantId != "" {
+		tx = tx.Where("tenant_id = ?", filter.TenantId)
+	}
+	if filter.Ids != nil {
+		tx = tx.Where("id in ?", filter.Ids)
+	}
+	if filter.Namespace != "" {
+		tx = tx.Where("namespace = ?", filter.Namespace)
+	}
+	if filter.Metadata != nil {
+		metadata, err := json.Marshal(filter.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		tx = tx.Where("metadata @> ?::jsonb", string(metadata))
+	}
+	if filter.Paused != nil {
+		tx = tx.Where("paused = ?", *filter.Paused)
+	}
+	return tx, nil
+}
+
+func (c *CockroachdbStore) ListTaskDefinitionsByFilter(filter pkg.TaskDefinitionFilter, offset, limit int) ([]pkg.TaskDefinition, error) {
+	taskDefinitionModels := []models.TaskDefinition{}
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		tx, err := applyTaskDefinitionFilter(tx.Preload(clause.Associations), filter)
+		if err != nil {
+			return err
+		}
+		return tx.Order("created_at").Offset(offset).Limit(limit).Find(&taskDefinitionModels).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error listing task definitions by filter")
+		return nil, err
+	}
+	return models.ToTaskDefinitions(taskDefinitionModels)
+}
+
+func (c *CockroachdbStore) CountTaskDefinitionsByFilter(filter pkg.TaskDefinitionFilter) (int64, error) {
+	var count int64
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		tx, err := applyTaskDefinitionFilter(tx.Model(&models.TaskDefinition{}), filter)
+		if err != nil {
+			return err
+		}
+		return tx.Count(&count).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error counting task definitions by filter")
+	}
+	return count, err
+}
+
+func (c *CockroachdbStore) DeleteTaskDefinitionsByFilter(filter pkg.TaskDefinitionFilter) error {
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		// an empty filter would delete every task definition
+		tx, err := applyTaskDefinitionFilter(tx.Session(&gorm.Session{AllowGlobalUpdate: false}), filter)
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&models.TaskDefinition{}).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error deleting task definitions by filter")
+	}
+	return err
+}
+
+func (c *CockroachdbStore) ListTaskDefinitionsToRewrap(keyId string, limit int) ([]pkg.TaskDefinition, error) {
+	taskDefinitionModels := []models.TaskDefinition{}
+	err := crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Preload(clause.Associations).Where("payload_envelope->>'key_id' != ?", keyId).Order("created_at").Limit(limit).Find(&taskDefinitionModels).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error listing task definitions to rewrap")
+		return nil, err
+	}
+	return models.ToTaskDefinitions(taskDefinitionModels)
+}
+
+func (c *CockroachdbStore) UpdateTaskDefinitionPayloadEnvelope(id *uuid.UUID, oldKeyId string, envelope pkg.PayloadEnvelope) error {
+	envelopeBytes, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	err = crdbgorm.ExecuteTx(context.Background(), c.db, nil, func(tx *gorm.DB) error {
+		return tx.Model(&models.TaskDefinition{}).Where("id = ? and payload_envelope->>'key_id' = ?", id, oldKeyId).Updates(map[string]interface{}{
+			"payload_envelope": gorm.Expr("?::jsonb", string(envelopeBytes)),
+			"version":          gorm.Expr("version + 1"),
+		}).Error
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error updating task definition payload envelope")
+	}
+	return err
+}
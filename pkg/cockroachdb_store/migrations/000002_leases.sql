@@ -0,0 +1,12 @@
+-- +goose Up
+create table scheduler_leases
+(
+    name string primary key,
+    created_at bigint not null,
+    updated_at bigint not null,
+    holder_id uuid not null,
+    expires_at timestamptz not null
+);
+
+-- +goose Down
+drop table scheduler_leases;
@@ -0,0 +1,17 @@
+-- +goose Up
+create table scheduler_nodes
+(
+    id uuid primary key,
+    created_at bigint not null,
+    updated_at bigint not null,
+    hostname string,
+    version string,
+    started_at timestamptz,
+    heartbeat_at timestamptz
+);
+
+alter table task_instances add column node_id uuid;
+
+-- +goose Down
+alter table task_instances drop column node_id;
+drop table scheduler_nodes;
@@ -0,0 +1,12 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+alter table task_definitions add column shard int not null default 0;
+
+-- must match pkg.ShardForId() and pkg.ShardCount
+update task_definitions set shard = fnv32a(id::string) % 256 where true;
+
+create index task_definitions_shard_idx on task_definitions (shard, next_fire_time);
+
+-- +goose Down
+drop index task_definitions@task_definitions_shard_idx;
+alter table task_definitions drop column shard;
@@ -0,0 +1,11 @@
+-- +goose Up
+create table scheduler_notifications
+(
+    id uuid primary key default gen_random_uuid(),
+    created_at bigint not null,
+    updated_at bigint not null,
+    task_definition_id uuid not null
+);
+
+-- +goose Down
+drop table scheduler_notifications;
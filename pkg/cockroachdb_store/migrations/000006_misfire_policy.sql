@@ -0,0 +1,7 @@
+-- +goose Up
+alter table task_definitions add column misfire_policy string not null default '';
+alter table task_definitions add column max_misfires int not null default 0;
+
+-- +goose Down
+alter table task_definitions drop column max_misfires;
+alter table task_definitions drop column misfire_policy;
@@ -0,0 +1,10 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+alter table task_instances add column fire_time timestamptz;
+alter table task_instances add column backfill bool not null default false;
+create index task_instances_fire_time_idx on task_instances (task_definition_id, fire_time);
+
+-- +goose Down
+drop index task_instances@task_instances_fire_time_idx;
+alter table task_instances drop column backfill;
+alter table task_instances drop column fire_time;
@@ -0,0 +1,5 @@
+-- +goose Up
+alter table cron_triggers add column location string not null default '';
+
+-- +goose Down
+alter table cron_triggers drop column location;
@@ -0,0 +1,14 @@
+-- +goose Up
+create table interval_triggers
+(
+    id uuid primary key default gen_random_uuid(),
+    created_at  bigint not null,
+    updated_at  bigint not null,
+    task_definition_id uuid not null references task_definitions (id) on delete cascade,
+    anchor timestamptz not null,
+    period int64 not null,
+    mode string not null
+);
+
+-- +goose Down
+drop table interval_triggers;
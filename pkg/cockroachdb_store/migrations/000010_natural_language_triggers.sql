@@ -0,0 +1,14 @@
+-- +goose Up
+create table natural_language_triggers
+(
+    id uuid primary key default gen_random_uuid(),
+    created_at  bigint not null,
+    updated_at  bigint not null,
+    task_definition_id uuid not null references task_definitions (id) on delete cascade,
+    text string not null,
+    normalized string not null,
+    location string not null default 'UTC'
+);
+
+-- +goose Down
+drop table natural_language_triggers;
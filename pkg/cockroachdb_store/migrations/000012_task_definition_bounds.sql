@@ -0,0 +1,11 @@
+-- +goose Up
+alter table task_definitions add column start_at timestamptz;
+alter table task_definitions add column end_at timestamptz;
+alter table task_definitions add column max_occurrences int not null default 0;
+alter table task_definitions add column occurrences int not null default 0;
+
+-- +goose Down
+alter table task_definitions drop column occurrences;
+alter table task_definitions drop column max_occurrences;
+alter table task_definitions drop column end_at;
+alter table task_definitions drop column start_at;
@@ -0,0 +1,18 @@
+-- +goose Up
+create table calendars
+(
+    name string primary key,
+    created_at  bigint not null,
+    updated_at  bigint not null,
+    location string not null default '',
+    dates jsonb,
+    weekly_windows jsonb,
+    ranges jsonb
+);
+alter table task_definitions add column calendar_name string not null default '';
+alter table task_definitions add column calendar_policy string not null default '';
+
+-- +goose Down
+alter table task_definitions drop column calendar_policy;
+alter table task_definitions drop column calendar_name;
+drop table calendars;
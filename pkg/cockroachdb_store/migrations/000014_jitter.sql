@@ -0,0 +1,5 @@
+-- +goose Up
+alter table task_definitions add column jitter int8 not null default 0;
+
+-- +goose Down
+alter table task_definitions drop column jitter;
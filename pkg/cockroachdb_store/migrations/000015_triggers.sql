@@ -0,0 +1,13 @@
+-- +goose Up
+create table triggers
+(
+    id uuid primary key default gen_random_uuid(),
+    created_at  bigint not null,
+    updated_at  bigint not null,
+    task_definition_id uuid not null references task_definitions (id) on delete cascade,
+    type string not null,
+    data bytes not null
+);
+
+-- +goose Down
+drop table triggers;
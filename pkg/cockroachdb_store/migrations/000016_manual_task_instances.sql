@@ -0,0 +1,7 @@
+-- +goose Up
+alter table task_instances add column manual bool not null default false;
+alter table task_instances add column metadata jsonb;
+
+-- +goose Down
+alter table task_instances drop column metadata;
+alter table task_instances drop column manual;
@@ -0,0 +1,35 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+create table workflow_runs
+(
+    id uuid primary key default gen_random_uuid(),
+    created_at  bigint not null,
+    updated_at  bigint not null,
+    root_task_definition_id uuid not null references task_definitions (id) on delete cascade,
+    fire_time timestamptz,
+    status string not null,
+    started_at timestamptz,
+    completed_at timestamptz,
+    index workflow_runs_root_task_definition_id_idx (root_task_definition_id, started_at desc)
+);
+alter table task_definitions add column depends_on jsonb;
+alter table task_definitions add column failure_policy string not null default '';
+alter table task_definitions add column max_attempts int8 not null default 0;
+create inverted index task_definitions_depends_on_idx on task_definitions (depends_on);
+alter table task_instances add column attempts int8 not null default 0;
+alter table task_instances add column failed_at timestamptz;
+alter table task_instances add column workflow_run_id uuid;
+alter table task_instances add column skipped bool not null default false;
+create index task_instances_workflow_run_id_idx on task_instances (workflow_run_id);
+
+-- +goose Down
+drop index task_instances@task_instances_workflow_run_id_idx;
+alter table task_instances drop column skipped;
+alter table task_instances drop column workflow_run_id;
+alter table task_instances drop column failed_at;
+alter table task_instances drop column attempts;
+drop index task_definitions@task_definitions_depends_on_idx;
+alter table task_definitions drop column max_attempts;
+alter table task_definitions drop column failure_policy;
+alter table task_definitions drop column depends_on;
+drop table workflow_runs;
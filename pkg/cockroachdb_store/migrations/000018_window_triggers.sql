@@ -0,0 +1,18 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+create table window_triggers
+(
+    id uuid primary key default gen_random_uuid(),
+    created_at  bigint not null,
+    updated_at  bigint not null,
+    task_definition_id uuid not null references task_definitions (id) on delete cascade,
+    expression string not null,
+    location string not null default '',
+    duration int8 not null,
+    slot int8 not null default 0
+);
+create index task_instances_execute_at_idx on task_instances (execute_at) where completed_at is null;
+
+-- +goose Down
+drop index task_instances@task_instances_execute_at_idx;
+drop table window_triggers;
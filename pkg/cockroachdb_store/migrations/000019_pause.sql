@@ -0,0 +1,7 @@
+-- +goose Up
+alter table task_definitions add column paused bool not null default false;
+alter table task_definitions add column paused_at timestamptz;
+
+-- +goose Down
+alter table task_definitions drop column paused_at;
+alter table task_definitions drop column paused;
@@ -0,0 +1,8 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+alter table task_definitions add column namespace string not null default '';
+create index task_definitions_namespace_idx on task_definitions (namespace);
+
+-- +goose Down
+drop index task_definitions@task_definitions_namespace_idx;
+alter table task_definitions drop column namespace;
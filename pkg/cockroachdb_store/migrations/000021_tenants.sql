@@ -0,0 +1,12 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+alter table task_definitions add column tenant_id string not null default '';
+create index task_definitions_tenant_id_idx on task_definitions (tenant_id, namespace);
+alter table task_instances add column tenant_id string not null default '';
+create index task_instances_tenant_id_idx on task_instances (tenant_id);
+
+-- +goose Down
+drop index task_instances@task_instances_tenant_id_idx;
+alter table task_instances drop column tenant_id;
+drop index task_definitions@task_definitions_tenant_id_idx;
+alter table task_definitions drop column tenant_id;
@@ -0,0 +1,8 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+alter table task_definitions add column key string not null default '';
+create unique index task_definitions_tenant_id_key_idx on task_definitions (tenant_id, key) where key != '';
+
+-- +goose Down
+drop index task_definitions@task_definitions_tenant_id_key_idx;
+alter table task_definitions drop column key;
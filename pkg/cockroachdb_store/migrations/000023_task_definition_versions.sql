@@ -0,0 +1,5 @@
+-- +goose Up
+alter table task_definitions add column version int not null default 1;
+
+-- +goose Down
+alter table task_definitions drop column version;
@@ -0,0 +1,7 @@
+-- +goose Up
+alter table task_definitions add column payload_type string not null default '';
+alter table task_definitions add column payload bytes;
+
+-- +goose Down
+alter table task_definitions drop column payload;
+alter table task_definitions drop column payload_type;
@@ -0,0 +1,5 @@
+-- +goose Up
+alter table task_definitions add column payload_envelope jsonb;
+
+-- +goose Down
+alter table task_definitions drop column payload_envelope;
@@ -0,0 +1,8 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+-- scheduled task instances are unique per fire time, duplicates created before this keep the oldest task instance
+delete from task_instances where id in (select id from (select id, row_number() over (partition by task_definition_id, fire_time order by created_at, id) as n from task_instances where not manual and workflow_run_id is null and fire_time is not null) where n > 1);
+create unique index task_instances_scheduled_fire_time_idx on task_instances (task_definition_id, fire_time) where not manual and workflow_run_id is null;
+
+-- +goose Down
+drop index task_instances@task_instances_scheduled_fire_time_idx;
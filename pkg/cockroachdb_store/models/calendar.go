@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/catalystsquad/go-scheduler/pkg"
+)
+
+type Calendar struct {
+	Name          string             `json:"name" gorm:"primaryKey"`
+	CreatedAt     int64              `json:"created_at,string" gorm:"autoCreateTime:nano"`
+	UpdatedAt     int64              `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
+	Location      string             `json:"location"`
+	Dates         []string           `json:"dates" gorm:"serializer:json"`
+	WeeklyWindows []pkg.WeeklyWindow `json:"weekly_windows" gorm:"serializer:json"`
+	Ranges        []pkg.TimeRange    `json:"ranges" gorm:"serializer:json"`
+}
+
+func (c Calendar) ToCalendar() (pkg.Calendar, error) {
+	var calendar pkg.Calendar
+	calendarModelJsonBytes, err := json.Marshal(c)
+	if err != nil {
+		return calendar, err
+	}
+	err = json.Unmarshal(calendarModelJsonBytes, &calendar)
+	return calendar, err
+}
+
+func ToCalendars(calendarModels []Calendar) ([]pkg.Calendar, error) {
+	calendars := []pkg.Calendar{}
+	for _, calendarModel := range calendarModels {
+		calendar, err := calendarModel.ToCalendar()
+		if err != nil {
+			return nil, err
+		}
+		calendars = append(calendars, calendar)
+	}
+	return calendars, nil
+}
+
+func GetCalendarModelFromCalendar(calendar pkg.Calendar) (*Calendar, error) {
+	calendarJsonBytes, err := json.Marshal(calendar)
+	if err != nil {
+		return nil, err
+	}
+	var calendarModel *Calendar
+	err = json.Unmarshal(calendarJsonBytes, &calendarModel)
+	return calendarModel, err
+}
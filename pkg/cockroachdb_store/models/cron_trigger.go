@@ -11,6 +11,7 @@ type CronTrigger struct {
 	CreatedAt        int64           `json:"created_at,string" gorm:"autoCreateTime:nano"`
 	UpdatedAt        int64           `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
 	Expression       string          `json:"expression"`
+	Location         string          `json:"location"`
 	TaskDefinitionId *uuid.UUID      `json:"task_definition_id"`
 	TaskDefinition   *TaskDefinition `json:"task"`
 }
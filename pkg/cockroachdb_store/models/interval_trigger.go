@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/google/uuid"
+	"time"
+)
+
+type IntervalTrigger struct {
+	Id               string          `json:"id" gorm:"primaryKey"`
+	CreatedAt        int64           `json:"created_at,string" gorm:"autoCreateTime:nano"`
+	UpdatedAt        int64           `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
+	Anchor           time.Time       `json:"anchor"`
+	Period           time.Duration   `json:"period"`
+	Mode             string          `json:"mode"`
+	TaskDefinitionId *uuid.UUID      `json:"task_definition_id"`
+	TaskDefinition   *TaskDefinition `json:"task"`
+}
+
+func GetIntervalTriggerModelFromTrigger(trigger *pkg.IntervalTrigger) (*IntervalTrigger, error) {
+	triggerJsonBytes, err := json.Marshal(trigger)
+	if err != nil {
+		return nil, err
+	}
+	if triggerJsonBytes == nil {
+		return nil, nil
+	}
+	var triggerModel *IntervalTrigger
+	err = json.Unmarshal(triggerJsonBytes, &triggerModel)
+	return triggerModel, err
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type Lease struct {
+	Name      string     `json:"name" gorm:"primaryKey"`
+	CreatedAt int64      `json:"created_at,string" gorm:"autoCreateTime:nano"`
+	UpdatedAt int64      `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
+	HolderId  *uuid.UUID `json:"holder_id"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (Lease) TableName() string {
+	return "scheduler_leases"
+}
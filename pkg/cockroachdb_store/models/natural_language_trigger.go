@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/google/uuid"
+)
+
+type NaturalLanguageTrigger struct {
+	Id               string          `json:"id" gorm:"primaryKey"`
+	CreatedAt        int64           `json:"created_at,string" gorm:"autoCreateTime:nano"`
+	UpdatedAt        int64           `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
+	Text             string          `json:"text"`
+	Normalized       string          `json:"normalized"`
+	Location         string          `json:"location"`
+	TaskDefinitionId *uuid.UUID      `json:"task_definition_id"`
+	TaskDefinition   *TaskDefinition `json:"task"`
+}
+
+func GetNaturalLanguageTriggerModelFromTrigger(trigger *pkg.NaturalLanguageTrigger) (*NaturalLanguageTrigger, error) {
+	triggerJsonBytes, err := json.Marshal(trigger)
+	if err != nil {
+		return nil, err
+	}
+	if triggerJsonBytes == nil {
+		return nil, nil
+	}
+	var triggerModel *NaturalLanguageTrigger
+	err = json.Unmarshal(triggerJsonBytes, &triggerModel)
+	return triggerModel, err
+}
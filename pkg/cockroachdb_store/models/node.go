@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/google/uuid"
+	"time"
+)
+
+type Node struct {
+	Id          *uuid.UUID `json:"id" gorm:"primaryKey"`
+	CreatedAt   int64      `json:"created_at,string" gorm:"autoCreateTime:nano"`
+	UpdatedAt   int64      `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
+	Hostname    string     `json:"hostname"`
+	Version     string     `json:"version"`
+	StartedAt   *time.Time `json:"started_at"`
+	HeartbeatAt *time.Time `json:"heartbeat_at"`
+}
+
+func (Node) TableName() string {
+	return "scheduler_nodes"
+}
+
+func (n Node) ToNode() (pkg.Node, error) {
+	var node pkg.Node
+	nodeModelJsonBytes, err := json.Marshal(n)
+	if err != nil {
+		return node, err
+	}
+	err = json.Unmarshal(nodeModelJsonBytes, &node)
+	return node, err
+}
+
+func ToNodes(nodeModels []Node) ([]pkg.Node, error) {
+	nodes := []pkg.Node{}
+	for _, nodeModel := range nodeModels {
+		node, err := nodeModel.ToNode()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func GetNodeModelFromNode(node pkg.Node) (*Node, error) {
+	nodeJsonBytes, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	var nodeModel *Node
+	err = json.Unmarshal(nodeJsonBytes, &nodeModel)
+	return nodeModel, err
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type Notification struct {
+	Id               *uuid.UUID `json:"id" gorm:"primaryKey;default:gen_random_uuid()"`
+	CreatedAt        int64      `json:"created_at,string" gorm:"autoCreateTime:nano"`
+	UpdatedAt        int64      `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
+	TaskDefinitionId *uuid.UUID `json:"task_definition_id"`
+}
+
+func (Notification) TableName() string {
+	return "scheduler_notifications"
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/google/uuid"
+)
+
+type RRuleTrigger struct {
+	Id               string          `json:"id" gorm:"primaryKey"`
+	CreatedAt        int64           `json:"created_at,string" gorm:"autoCreateTime:nano"`
+	UpdatedAt        int64           `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
+	Recurrence       string          `json:"recurrence"`
+	Location         string          `json:"location"`
+	TaskDefinitionId *uuid.UUID      `json:"task_definition_id"`
+	TaskDefinition   *TaskDefinition `json:"task"`
+}
+
+func (RRuleTrigger) TableName() string {
+	return "rrule_triggers"
+}
+
+func GetRRuleTriggerModelFromTrigger(trigger *pkg.RRuleTrigger) (*RRuleTrigger, error) {
+	triggerJsonBytes, err := json.Marshal(trigger)
+	if err != nil {
+		return nil, err
+	}
+	if triggerJsonBytes == nil {
+		return nil, nil
+	}
+	var triggerModel *RRuleTrigger
+	err = json.Unmarshal(triggerJsonBytes, &triggerModel)
+	return triggerModel, err
+}
@@ -11,20 +11,47 @@ import (
 )
 
 type TaskDefinition struct {
-	Id                  *uuid.UUID          `json:"id" gorm:"primaryKey"`
-	CreatedAt           int64               `json:"created_at,string" gorm:"autoCreateTime:nano"`
-	UpdatedAt           int64               `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
-	Metadata            gormjsonb.JSONB     `json:"metadata" gorm:"type:jsonb"`
-	ExpireAfter         *time.Duration      `json:"expire_after"`
-	ExpireAfterInterval *string             `json:"expire_after_interval"`
-	InProgress          bool                `json:"in_progress_at"`
-	LastFireTime        *time.Time          `json:"last_fire_time"`
-	NextFireTime        *time.Time          `json:"next_fire_time"`
-	ExecuteOnceTrigger  *ExecuteOnceTrigger `json:"execute_once_trigger" gorm:"foreignKey:Id"`
-	CronTrigger         *CronTrigger        `json:"cron_trigger" gorm:"foreignKey:Id"`
-	CompletedAt         *time.Time          `json:"completed_at"`
-	TaskInstances       []TaskInstance      `json:"task_instances"`
-	Recurring           bool
+	Id                     *uuid.UUID              `json:"id" gorm:"primaryKey"`
+	Namespace              string                  `json:"namespace"`
+	TenantId               string                  `json:"tenant_id"`
+	Key                    string                  `json:"key"`
+	Version                int64                   `json:"version"`
+	CreatedAt              int64                   `json:"created_at,string" gorm:"autoCreateTime:nano"`
+	UpdatedAt              int64                   `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
+	Metadata               gormjsonb.JSONB         `json:"metadata" gorm:"type:jsonb"`
+	PayloadType            string                  `json:"payload_type"`
+	Payload                []byte                  `json:"payload"`
+	PayloadEnvelope        *pkg.PayloadEnvelope    `json:"payload_envelope" gorm:"serializer:json"`
+	ExpireAfter            *time.Duration          `json:"expire_after"`
+	ExpireAfterInterval    *string                 `json:"expire_after_interval"`
+	InProgress             bool                    `json:"in_progress_at"`
+	LastFireTime           *time.Time              `json:"last_fire_time"`
+	NextFireTime           *time.Time              `json:"next_fire_time"`
+	ExecuteOnceTrigger     *ExecuteOnceTrigger     `json:"execute_once_trigger" gorm:"foreignKey:Id"`
+	CronTrigger            *CronTrigger            `json:"cron_trigger" gorm:"foreignKey:Id"`
+	IntervalTrigger        *IntervalTrigger        `json:"interval_trigger" gorm:"foreignKey:Id"`
+	NaturalLanguageTrigger *NaturalLanguageTrigger `json:"natural_language_trigger" gorm:"foreignKey:Id"`
+	RRuleTrigger           *RRuleTrigger           `json:"rrule_trigger" gorm:"foreignKey:Id"`
+	WindowTrigger          *WindowTrigger          `json:"window_trigger" gorm:"foreignKey:Id"`
+	Trigger                *Trigger                `json:"trigger" gorm:"foreignKey:Id"`
+	CompletedAt            *time.Time              `json:"completed_at"`
+	TaskInstances          []TaskInstance          `json:"task_instances"`
+	Recurring              bool
+	Shard                  int           `json:"shard"`
+	MisfirePolicy          string        `json:"misfire_policy"`
+	MaxMisfires            int           `json:"max_misfires"`
+	StartAt                *time.Time    `json:"start_at"`
+	EndAt                  *time.Time    `json:"end_at"`
+	MaxOccurrences         int           `json:"max_occurrences"`
+	Occurrences            int           `json:"occurrences"`
+	CalendarName           string        `json:"calendar_name"`
+	CalendarPolicy         string        `json:"calendar_policy"`
+	Jitter                 time.Duration `json:"jitter"`
+	DependsOn              []*uuid.UUID  `json:"depends_on" gorm:"serializer:json"`
+	FailurePolicy          string        `json:"failure_policy"`
+	MaxAttempts            int           `json:"max_attempts"`
+	Paused                 bool          `json:"paused"`
+	PausedAt               *time.Time    `json:"paused_at"`
 }
 
 var nilUuidString = uuid.Nil.String()
@@ -46,13 +73,6 @@ func (t TaskDefinition) ToTaskDefinition() (pkg.TaskDefinition, error) {
 		return task, err
 	}
 	err = json.Unmarshal(taskModelJsonBytes, &task)
-	if t.CronTrigger != nil {
-		cronTrigger, err := pkg.NewCronTrigger(t.CronTrigger.Expression)
-		if err != nil {
-			return task, err
-		}
-		task.CronTrigger = cronTrigger
-	}
 	return task, err
 }
 
@@ -78,9 +98,34 @@ func GetTaskDefinitionModelFromTaskDefinition(task pkg.TaskDefinition) (*TaskDef
 	if err != nil {
 		return nil, err
 	}
+	intervalTriggerModel, err := GetIntervalTriggerModelFromTrigger(task.IntervalTrigger)
+	if err != nil {
+		return nil, err
+	}
+	naturalLanguageTriggerModel, err := GetNaturalLanguageTriggerModelFromTrigger(task.NaturalLanguageTrigger)
+	if err != nil {
+		return nil, err
+	}
+	rruleTriggerModel, err := GetRRuleTriggerModelFromTrigger(task.RRuleTrigger)
+	if err != nil {
+		return nil, err
+	}
+	windowTriggerModel, err := GetWindowTriggerModelFromTrigger(task.WindowTrigger)
+	if err != nil {
+		return nil, err
+	}
+	triggerModel, err := GetTriggerModelFromTrigger(task.Trigger)
+	if err != nil {
+		return nil, err
+	}
 	// nullify triggers
 	task.ExecuteOnceTrigger = nil
 	task.CronTrigger = nil
+	task.IntervalTrigger = nil
+	task.NaturalLanguageTrigger = nil
+	task.RRuleTrigger = nil
+	task.WindowTrigger = nil
+	task.Trigger = nil
 	// marshal the task model
 	taskJsonBytes, err := json.Marshal(task)
 	if err != nil {
@@ -100,6 +145,26 @@ func GetTaskDefinitionModelFromTaskDefinition(task pkg.TaskDefinition) (*TaskDef
 	if taskModel.CronTrigger != nil {
 		taskModel.CronTrigger.TaskDefinition = taskModel
 	}
+	taskModel.IntervalTrigger = intervalTriggerModel
+	if taskModel.IntervalTrigger != nil {
+		taskModel.IntervalTrigger.TaskDefinition = taskModel
+	}
+	taskModel.NaturalLanguageTrigger = naturalLanguageTriggerModel
+	if taskModel.NaturalLanguageTrigger != nil {
+		taskModel.NaturalLanguageTrigger.TaskDefinition = taskModel
+	}
+	taskModel.RRuleTrigger = rruleTriggerModel
+	if taskModel.RRuleTrigger != nil {
+		taskModel.RRuleTrigger.TaskDefinition = taskModel
+	}
+	taskModel.WindowTrigger = windowTriggerModel
+	if taskModel.WindowTrigger != nil {
+		taskModel.WindowTrigger.TaskDefinition = taskModel
+	}
+	taskModel.Trigger = triggerModel
+	if taskModel.Trigger != nil {
+		taskModel.Trigger.TaskDefinition = taskModel
+	}
 	// set expiration interval
 	if taskModel.ExpireAfter != nil {
 		interval := taskModel.ExpireAfter.String()
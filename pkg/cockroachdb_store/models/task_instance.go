@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/catalystsquad/app-utils-go/logging"
 	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/dariubs/gorm-jsonb"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -11,15 +12,25 @@ import (
 )
 
 type TaskInstance struct {
-	Id               *uuid.UUID      `json:"id" gorm:"primaryKey"`
-	CreatedAt        int64           `json:"created_at,string" gorm:"autoCreateTime:nano"`
-	UpdatedAt        int64           `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
-	ExpiresAt        *time.Time      `json:"expires_at"`
-	ExecuteAt        *time.Time      `json:"execute_at"`
-	StartedAt        *time.Time      `json:"started_at"`
-	CompletedAt      *time.Time      `json:"completed_at"`
-	TaskDefinitionId *uuid.UUID      `json:"task_definition_id"`
-	TaskDefinition   *TaskDefinition `json:"task_definition"`
+	Id               *uuid.UUID       `json:"id" gorm:"primaryKey"`
+	TenantId         string           `json:"tenant_id"`
+	CreatedAt        int64            `json:"created_at,string" gorm:"autoCreateTime:nano"`
+	UpdatedAt        int64            `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
+	ExpiresAt        *time.Time       `json:"expires_at"`
+	ExecuteAt        *time.Time       `json:"execute_at"`
+	StartedAt        *time.Time       `json:"started_at"`
+	CompletedAt      *time.Time       `json:"completed_at"`
+	NodeId           *uuid.UUID       `json:"node_id"`
+	FireTime         *time.Time       `json:"fire_time"`
+	Backfill         bool             `json:"backfill"`
+	Manual           bool             `json:"manual"`
+	Metadata         *gormjsonb.JSONB `json:"metadata" gorm:"type:jsonb"`
+	Attempts         int              `json:"attempts"`
+	FailedAt         *time.Time       `json:"failed_at"`
+	WorkflowRunId    *uuid.UUID       `json:"workflow_run_id"`
+	Skipped          bool             `json:"skipped"`
+	TaskDefinitionId *uuid.UUID       `json:"task_definition_id"`
+	TaskDefinition   *TaskDefinition  `json:"task_definition"`
 }
 
 func (t *TaskInstance) BeforeCreate(tx *gorm.DB) error {
@@ -70,5 +81,6 @@ func GetTaskInstanceModelFromTaskInstance(taskInstance pkg.TaskInstance) (*TaskI
 		return nil, err
 	}
 	taskInstanceModel.TaskDefinitionId = taskInstanceModel.TaskDefinition.Id
+	taskInstanceModel.TenantId = taskInstance.TaskDefinition.TenantId
 	return taskInstanceModel, err
 }
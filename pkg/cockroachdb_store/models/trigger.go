@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/google/uuid"
+)
+
+// Trigger stores custom triggers registered with pkg.RegisterTrigger(), as their registered type name and encoded data
+type Trigger struct {
+	Id               string          `json:"id" gorm:"primaryKey"`
+	CreatedAt        int64           `json:"created_at,string" gorm:"autoCreateTime:nano"`
+	UpdatedAt        int64           `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
+	Type             string          `json:"type"`
+	Data             []byte          `json:"data"`
+	TaskDefinitionId *uuid.UUID      `json:"task_definition_id"`
+	TaskDefinition   *TaskDefinition `json:"task"`
+}
+
+func GetTriggerModelFromTrigger(trigger *pkg.RegisteredTrigger) (*Trigger, error) {
+	triggerJsonBytes, err := json.Marshal(trigger)
+	if err != nil {
+		return nil, err
+	}
+	if triggerJsonBytes == nil {
+		return nil, nil
+	}
+	var triggerModel *Trigger
+	err = json.Unmarshal(triggerJsonBytes, &triggerModel)
+	return triggerModel, err
+}
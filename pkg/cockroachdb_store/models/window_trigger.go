@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/google/uuid"
+	"time"
+)
+
+type WindowTrigger struct {
+	Id               string          `json:"id" gorm:"primaryKey"`
+	CreatedAt        int64           `json:"created_at,string" gorm:"autoCreateTime:nano"`
+	UpdatedAt        int64           `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
+	Expression       string          `json:"expression"`
+	Location         string          `json:"location"`
+	Duration         time.Duration   `json:"duration"`
+	Slot             time.Duration   `json:"slot"`
+	TaskDefinitionId *uuid.UUID      `json:"task_definition_id"`
+	TaskDefinition   *TaskDefinition `json:"task"`
+}
+
+func GetWindowTriggerModelFromTrigger(trigger *pkg.WindowTrigger) (*WindowTrigger, error) {
+	triggerJsonBytes, err := json.Marshal(trigger)
+	if err != nil {
+		return nil, err
+	}
+	if triggerJsonBytes == nil {
+		return nil, nil
+	}
+	var triggerModel *WindowTrigger
+	err = json.Unmarshal(triggerJsonBytes, &triggerModel)
+	return triggerModel, err
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/google/uuid"
+	"time"
+)
+
+type WorkflowRun struct {
+	Id                   *uuid.UUID `json:"id" gorm:"primaryKey"`
+	CreatedAt            int64      `json:"created_at,string" gorm:"autoCreateTime:nano"`
+	UpdatedAt            int64      `json:"updated_at,string" gorm:"autoUpdateTime:nano"`
+	RootTaskDefinitionId *uuid.UUID `json:"root_task_definition_id"`
+	FireTime             *time.Time `json:"fire_time"`
+	Status               string     `json:"status"`
+	StartedAt            *time.Time `json:"started_at"`
+	CompletedAt          *time.Time `json:"completed_at"`
+}
+
+func (w WorkflowRun) ToWorkflowRun() (pkg.WorkflowRun, error) {
+	var workflowRun pkg.WorkflowRun
+	workflowRunModelJsonBytes, err := json.Marshal(w)
+	if err != nil {
+		return workflowRun, err
+	}
+	err = json.Unmarshal(workflowRunModelJsonBytes, &workflowRun)
+	return workflowRun, err
+}
+
+func ToWorkflowRuns(workflowRunModels []WorkflowRun) ([]pkg.WorkflowRun, error) {
+	workflowRuns := []pkg.WorkflowRun{}
+	for _, workflowRunModel := range workflowRunModels {
+		workflowRun, err := workflowRunModel.ToWorkflowRun()
+		if err != nil {
+			return nil, err
+		}
+		workflowRuns = append(workflowRuns, workflowRun)
+	}
+	return workflowRuns, nil
+}
+
+func GetWorkflowRunModelFromWorkflowRun(workflowRun pkg.WorkflowRun) (*WorkflowRun, error) {
+	workflowRunJsonBytes, err := json.Marshal(workflowRun)
+	if err != nil {
+		return nil, err
+	}
+	var workflowRunModel *WorkflowRun
+	err = json.Unmarshal(workflowRunJsonBytes, &workflowRunModel)
+	return workflowRunModel, err
+}
@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"encoding/json"
 	"github.com/gorhill/cronexpr"
 	"time"
 )
@@ -8,20 +9,86 @@ import (
 const oncePerSecondCron = "* * * * * * *"
 
 type CronTrigger struct {
-	Expression string               `json:"expression"`
-	cronexpr   *cronexpr.Expression `json:"cronexpr"`
+	Expression string `json:"expression"`
+	// Location is the IANA time zone name the expression is evaluated in, UTC if empty
+	Location string `json:"location"`
+	cronexpr *cronexpr.Expression
+	location *time.Location
 }
 
+// GetFireTime() evaluates the expression against wall clock time in the trigger's location. Wall clock times that are
+// skipped by a daylight saving transition fire when the transition happens, and wall clock times that are repeated by a
+// daylight saving transition only fire the first time they occur.
 func (t CronTrigger) GetFireTime(from time.Time) *time.Time {
-	nextFireTime := t.cronexpr.Next(from)
-	return &nextFireTime
+	location := t.getLocation()
+	from = from.In(location)
+	// cronexpr evaluates in the location of the time it's given, UTC has no transitions so evaluate the wall clock there
+	wallClock := toWallClock(from)
+	for {
+		wallClock = t.cronexpr.Next(wallClock)
+		if wallClock.IsZero() {
+			// the expression has no more occurrences
+			return nil
+		}
+		fireTime := fromWallClock(wallClock, location)
+		// a repeated wall clock time maps to its first occurrence, which is before from when from is in the repeat
+		if fireTime.After(from) {
+			return &fireTime
+		}
+	}
 }
 
 func (t CronTrigger) IsRecurring() bool {
 	return true
 }
 
+// UnmarshalJSON() rebuilds the parsed expression and location, which aren't marshalled
+func (t *CronTrigger) UnmarshalJSON(bytes []byte) error {
+	type cronTriggerJson CronTrigger
+	unmarshalled := cronTriggerJson{}
+	err := json.Unmarshal(bytes, &unmarshalled)
+	if err != nil {
+		return err
+	}
+	location, err := time.LoadLocation(unmarshalled.Location)
+	if err != nil {
+		return err
+	}
+	trigger, err := NewCronTriggerWithLocation(unmarshalled.Expression, location)
+	if err != nil {
+		return err
+	}
+	*t = *trigger
+	return nil
+}
+
+// In() returns a copy of the trigger evaluated in the given location
+func (t CronTrigger) In(location *time.Location) *CronTrigger {
+	t.location = location
+	t.Location = location.String()
+	return &t
+}
+
+func (t CronTrigger) getLocation() *time.Location {
+	if t.location != nil {
+		return t.location
+	}
+	if t.Location != "" {
+		location, err := time.LoadLocation(t.Location)
+		if err == nil {
+			return location
+		}
+	}
+	return time.UTC
+}
+
 func NewCronTrigger(cronExpression string) (*CronTrigger, error) {
+	return NewCronTriggerWithLocation(cronExpression, nil)
+}
+
+// NewCronTriggerWithLocation() returns a cron trigger evaluated in the given location, a nil location uses the
+// scheduler's default location, which is UTC unless set with WithLocation()
+func NewCronTriggerWithLocation(cronExpression string, location *time.Location) (*CronTrigger, error) {
 	// default to once per second
 	if cronExpression == "" {
 		cronExpression = oncePerSecondCron
@@ -30,8 +97,44 @@ func NewCronTrigger(cronExpression string) (*CronTrigger, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &CronTrigger{
+	trigger := &CronTrigger{
 		Expression: cronExpression,
 		cronexpr:   cronexpr,
-	}, nil
+	}
+	if location != nil {
+		trigger = trigger.In(location)
+	}
+	return trigger, nil
+}
+
+// toWallClock() returns the wall clock time of t as a UTC time
+func toWallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// fromWallClock() returns the instant the wall clock time occurs in the location. Skipped wall clock times return the
+// instant of the transition that skipped them, repeated wall clock times return their first occurrence.
+func fromWallClock(wallClock time.Time, location *time.Location) time.Time {
+	t := time.Date(wallClock.Year(), wallClock.Month(), wallClock.Day(), wallClock.Hour(), wallClock.Minute(), wallClock.Second(), wallClock.Nanosecond(), location)
+	zoneStart, zoneEnd := t.ZoneBounds()
+	if normalized := toWallClock(t); !normalized.Equal(wallClock) {
+		// skipped, t was normalized to one side of the transition
+		if normalized.Before(wallClock) {
+			return zoneEnd
+		}
+		return zoneStart
+	}
+	if zoneStart.IsZero() {
+		return t
+	}
+	// if the clocks went back at the start of this zone, the wall clock time may also have occurred before the transition
+	_, offset := t.Zone()
+	_, previousOffset := zoneStart.Add(-time.Nanosecond).Zone()
+	if previousOffset > offset {
+		earlier := t.Add(-time.Duration(previousOffset-offset) * time.Second)
+		if earlier.Before(zoneStart) && toWallClock(earlier.In(location)).Equal(wallClock) {
+			return earlier
+		}
+	}
+	return t
 }
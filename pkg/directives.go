@@ -0,0 +1,128 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/catalystsquad/app-utils-go/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// RetryAfterError tells the scheduler to run the task instance again after Delay, it counts as a failed attempt
+type RetryAfterError struct {
+	Delay time.Duration
+}
+
+func (e RetryAfterError) Error() string {
+	return fmt.Sprintf("retry after %s", e.Delay)
+}
+
+// RetryAfter() returns a directive for the handler to return, the task instance is run again after the delay instead
+// of when it expires
+func RetryAfter(delay time.Duration) error {
+	return RetryAfterError{Delay: delay}
+}
+
+// SnoozeError tells the scheduler to run the task instance again at Until, it doesn't count as a failed attempt
+type SnoozeError struct {
+	Until time.Time
+}
+
+func (e SnoozeError) Error() string {
+	return fmt.Sprintf("snooze until %s", e.Until.Format(time.RFC3339))
+}
+
+// SnoozeUntil() returns a directive for the handler to return, the task instance is run again at until without using
+// up an attempt
+func SnoozeUntil(until time.Time) error {
+	return SnoozeError{Until: until}
+}
+
+// PermanentFailureError tells the scheduler the task instance will never succeed
+type PermanentFailureError struct {
+	Err error
+}
+
+func (e PermanentFailureError) Error() string {
+	return fmt.Sprintf("permanent failure: %s", e.Err)
+}
+
+func (e PermanentFailureError) Unwrap() error {
+	return e.Err
+}
+
+// PermanentFailure() returns a directive for the handler to return, the task instance is marked failed right away
+// without using up its remaining attempts
+func PermanentFailure(err error) error {
+	return PermanentFailureError{Err: err}
+}
+
+// RescheduleError tells the scheduler the task instance succeeded, and to set its task definition's next fire time to
+// At
+type RescheduleError struct {
+	At time.Time
+}
+
+func (e RescheduleError) Error() string {
+	return fmt.Sprintf("reschedule at %s", e.At.Format(time.RFC3339))
+}
+
+// RescheduleAt() returns a directive for the handler to return, the task instance is completed and its task
+// definition's next fire time is set to at. Task definitions that have completed, like ones that fire once, fire again
+// at at.
+func RescheduleAt(at time.Time) error {
+	return RescheduleError{At: at}
+}
+
+// handleTaskInstanceError() interprets the error returned by the handler. Directives can be wrapped, errors that aren't
+// directives are run again once the task instance expires, until they've used up their task definition's max attempts.
+func (s *Scheduler) handleTaskInstanceError(taskInstance TaskInstance, err error) {
+	var permanentFailure PermanentFailureError
+	var reschedule RescheduleError
+	var snooze SnoozeError
+	var retryAfter RetryAfterError
+	switch {
+	case errors.As(err, &permanentFailure):
+		s.failTaskInstance(taskInstance)
+	case errors.As(err, &reschedule):
+		if len(taskInstance.TaskDefinition.DependsOn) > 0 {
+			logging.Log.WithFields(logrus.Fields{"task_definition_id": taskInstance.TaskDefinition.Id}).Warn("task definitions with dependencies can't be rescheduled, completing the task instance")
+			s.completeTaskInstance(taskInstance, nil)
+			return
+		}
+		s.completeTaskInstance(taskInstance, &reschedule.At)
+	case errors.As(err, &snooze):
+		// snoozing isn't a failed attempt
+		taskInstance.Attempts--
+		s.runTaskInstanceAt(taskInstance, snooze.Until)
+	case errors.As(err, &retryAfter):
+		if s.attemptsUsedUp(taskInstance) {
+			s.failTaskInstance(taskInstance)
+			return
+		}
+		s.runTaskInstanceAt(taskInstance, time.Now().Add(retryAfter.Delay))
+	default:
+		if s.attemptsUsedUp(taskInstance) {
+			s.failTaskInstance(taskInstance)
+		}
+	}
+}
+
+func (s *Scheduler) attemptsUsedUp(taskInstance TaskInstance) bool {
+	maxAttempts := taskInstance.TaskDefinition.MaxAttempts
+	return maxAttempts > 0 && taskInstance.Attempts >= maxAttempts
+}
+
+// runTaskInstanceAt() releases the task instance so that it's run again at executeAt, by whichever node picks it up
+func (s *Scheduler) runTaskInstanceAt(taskInstance TaskInstance, executeAt time.Time) {
+	expiresAt := executeAt.Add(taskInstance.TaskDefinition.ExpireAfter)
+	taskInstance.ExecuteAt = &executeAt
+	taskInstance.ExpiresAt = &expiresAt
+	taskInstance.StartedAt = nil
+	taskInstance.NodeId = nil
+	err := s.store.UpsertTaskInstance(taskInstance)
+	if err != nil {
+		logging.Log.WithError(err).WithFields(logrus.Fields{"task_instance_id": taskInstance.Id, "task_definition_id": taskInstance.TaskDefinition.Id}).Error("error setting task instance execute_at")
+	}
+}
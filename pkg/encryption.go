@@ -0,0 +1,157 @@
+package pkg
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+
+	"github.com/catalystsquad/app-utils-go/logging"
+	"github.com/joomcode/errorx"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	dataKeySize     = 32
+	rewrapBatchSize = 100
+)
+
+// PayloadEnvelope holds the data key an encrypted payload was encrypted with, wrapped by the key provider's key with
+// KeyId
+type PayloadEnvelope struct {
+	KeyId      string `json:"key_id"`
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+// WithEncryption() encrypts task definition payloads before they're stored, and decrypts them before they're passed to
+// the handler. Each payload is encrypted with its own data key, which is wrapped by the key provider. Metadata isn't
+// encrypted so it stays queryable, keep sensitive fields in the payload.
+func WithEncryption(keyProvider KeyProviderInterface) SchedulerOpt {
+	return func(s *Scheduler) {
+		s.keyProvider = keyProvider
+	}
+}
+
+// EncryptPayload() encrypts the task definition's payload with a new data key, task definitions without a payload, or
+// whose payload is already encrypted, are returned as is. Task definitions read from the scheduler keep their envelope,
+// if their payload was replaced since it's encrypted again. The task definition must have an id, the payload can't be
+// decrypted for any other task definition.
+func EncryptPayload(keyProvider KeyProviderInterface, task TaskDefinition) (TaskDefinition, error) {
+	if len(task.Payload) == 0 {
+		task.PayloadEnvelope = nil
+		return task, nil
+	}
+	if task.Id == nil {
+		return task, errorx.IllegalArgument.New("an id must be provided")
+	}
+	if task.PayloadEnvelope != nil {
+		aead, err := unwrapEnvelope(keyProvider, *task.PayloadEnvelope)
+		if err != nil {
+			return task, err
+		}
+		// the envelope's data key is valid, so a payload that doesn't decrypt with it isn't the payload it encrypted
+		if _, err = open(aead, task.Payload, task.Id[:]); err == nil {
+			return task, nil
+		}
+	}
+	dataKey := make([]byte, dataKeySize)
+	_, err := io.ReadFull(rand.Reader, dataKey)
+	if err != nil {
+		return task, err
+	}
+	aead, err := newAead(dataKey)
+	if err != nil {
+		return task, err
+	}
+	payload, err := seal(aead, task.Payload, task.Id[:])
+	if err != nil {
+		return task, err
+	}
+	keyId, wrappedKey, err := keyProvider.WrapKey(dataKey)
+	if err != nil {
+		return task, err
+	}
+	task.Payload = payload
+	task.PayloadEnvelope = &PayloadEnvelope{KeyId: keyId, WrappedKey: wrappedKey}
+	return task, nil
+}
+
+// DecryptPayload() decrypts the task definition's payload, task definitions whose payload isn't encrypted are returned
+// as is
+func DecryptPayload(keyProvider KeyProviderInterface, task TaskDefinition) (TaskDefinition, error) {
+	if task.PayloadEnvelope == nil {
+		return task, nil
+	}
+	if task.Id == nil {
+		return task, errorx.IllegalArgument.New("an id must be provided")
+	}
+	aead, err := unwrapEnvelope(keyProvider, *task.PayloadEnvelope)
+	if err != nil {
+		return task, err
+	}
+	payload, err := open(aead, task.Payload, task.Id[:])
+	if err != nil {
+		return task, errorx.Decorate(err, "error decrypting payload of task definition %s", task.Id)
+	}
+	task.Payload = payload
+	task.PayloadEnvelope = nil
+	return task, nil
+}
+
+// unwrapEnvelope() returns the cipher for the envelope's data key
+func unwrapEnvelope(keyProvider KeyProviderInterface, envelope PayloadEnvelope) (cipher.AEAD, error) {
+	dataKey, err := keyProvider.UnwrapKey(envelope.KeyId, envelope.WrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	return newAead(dataKey)
+}
+
+// RewrapPayloadKeys() rewraps the data keys of payloads that were wrapped with a key other than the key provider's
+// current key, the payloads themselves aren't re-encrypted. Call it after rotating keys, old keys can be removed from
+// the key provider once it returns. Returns the number of task definitions rewrapped.
+func (s *Scheduler) RewrapPayloadKeys() (int, error) {
+	if s.keyProvider == nil {
+		return 0, errorx.IllegalState.New("encryption isn't enabled")
+	}
+	keyId := s.keyProvider.CurrentKeyId()
+	rewrapped := 0
+	for {
+		definitions, err := s.store.ListTaskDefinitionsToRewrap(keyId, rewrapBatchSize)
+		if err != nil || len(definitions) == 0 {
+			return rewrapped, err
+		}
+		for _, definition := range definitions {
+			dataKey, err := s.keyProvider.UnwrapKey(definition.PayloadEnvelope.KeyId, definition.PayloadEnvelope.WrappedKey)
+			if err != nil {
+				return rewrapped, err
+			}
+			newKeyId, wrappedKey, err := s.keyProvider.WrapKey(dataKey)
+			if err != nil {
+				return rewrapped, err
+			}
+			err = s.store.UpdateTaskDefinitionPayloadEnvelope(definition.Id, definition.PayloadEnvelope.KeyId, PayloadEnvelope{KeyId: newKeyId, WrappedKey: wrappedKey})
+			if err != nil {
+				return rewrapped, err
+			}
+			rewrapped++
+		}
+		logging.Log.WithFields(logrus.Fields{"rewrapped": rewrapped, "key_id": keyId}).Info("rewrapped payload keys")
+	}
+}
+
+// decryptTaskInstance() returns the task instance with its task definition's payload decrypted for the handler.
+// Payloads that can't ever be decrypted fail the task instance permanently, other errors are retried.
+func (s *Scheduler) decryptTaskInstance(taskInstance TaskInstance) (TaskInstance, error) {
+	if s.keyProvider == nil {
+		return taskInstance, nil
+	}
+	definition, err := DecryptPayload(s.keyProvider, taskInstance.TaskDefinition)
+	if errorx.IsOfType(err, DecryptionFailed) {
+		return taskInstance, PermanentFailure(err)
+	}
+	if err != nil {
+		return taskInstance, err
+	}
+	taskInstance.TaskDefinition = definition
+	return taskInstance, nil
+}
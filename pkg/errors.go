@@ -0,0 +1,26 @@
+package pkg
+
+import (
+	"github.com/joomcode/errorx"
+)
+
+// SchedulerErrors is the namespace of the scheduler's typed errors, check for them with errorx.IsOfType()
+var SchedulerErrors = errorx.NewNamespace("scheduler")
+
+var (
+	// TaskDefinitionNotFound is returned by tenant views for task definitions that don't exist, or belong to another
+	// tenant
+	TaskDefinitionNotFound = SchedulerErrors.NewType("task_definition_not_found", errorx.NotFound())
+	// TenantMismatch is returned by tenant views for task definitions that belong to another tenant
+	TenantMismatch = SchedulerErrors.NewType("tenant_mismatch")
+	// DuplicateKey is returned when a task definition's key is already used by another task definition in its tenant
+	DuplicateKey = SchedulerErrors.NewType("duplicate_key")
+	// VersionConflict is returned when a task definition is updated with a version that isn't its current version,
+	// it's been changed since it was read
+	VersionConflict = SchedulerErrors.NewType("version_conflict")
+	// QuotaExceeded is returned when a tenant is over its quota
+	QuotaExceeded = SchedulerErrors.NewType("quota_exceeded")
+	// DecryptionFailed is returned when a payload or data key can't be decrypted, because the key it was encrypted
+	// with is unknown or it doesn't authenticate. Retrying won't help.
+	DecryptionFailed = SchedulerErrors.NewType("decryption_failed")
+)
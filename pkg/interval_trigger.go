@@ -0,0 +1,78 @@
+package pkg
+
+import (
+	"time"
+
+	"github.com/joomcode/errorx"
+)
+
+type IntervalMode string
+
+const (
+	// IntervalModeFixedRate fires every period counted from the anchor, regardless of how long task instances take
+	IntervalModeFixedRate IntervalMode = "fixed_rate"
+	// IntervalModeFixedDelay fires a period after the previous task instance completed
+	IntervalModeFixedDelay IntervalMode = "fixed_delay"
+)
+
+type IntervalTrigger struct {
+	Anchor time.Time     `json:"anchor"`
+	Period time.Duration `json:"period"`
+	Mode   IntervalMode  `json:"mode"`
+}
+
+func (t IntervalTrigger) GetFireTime(from time.Time) *time.Time {
+	if t.Period <= 0 {
+		// invalid, rejected on upsert
+		return nil
+	}
+	if from.Before(t.Anchor) {
+		return &t.Anchor
+	}
+	if t.Mode == IntervalModeFixedDelay {
+		fireTime := from.Add(t.Period)
+		return &fireTime
+	}
+	// the first multiple of the period after from
+	periods := from.Sub(t.Anchor)/t.Period + 1
+	fireTime := t.Anchor.Add(periods * t.Period)
+	return &fireTime
+}
+
+func (t IntervalTrigger) IsRecurring() bool {
+	return true
+}
+
+func (t IntervalTrigger) FiresAfterCompletion() bool {
+	return t.Mode == IntervalModeFixedDelay
+}
+
+// NewIntervalTrigger() returns a trigger that fires every period. The first fire time is the anchor, or a period
+// after now for fixed delay triggers whose anchor has passed. Mode defaults to fixed rate.
+func NewIntervalTrigger(anchor time.Time, period time.Duration, mode IntervalMode) (*IntervalTrigger, error) {
+	if mode == "" {
+		mode = IntervalModeFixedRate
+	}
+	trigger := &IntervalTrigger{
+		Anchor: anchor,
+		Period: period,
+		Mode:   mode,
+	}
+	err := trigger.validate()
+	if err != nil {
+		return nil, err
+	}
+	return trigger, nil
+}
+
+// validate() checks the period and mode, task definitions are validated with it too because triggers can be built
+// without NewIntervalTrigger(). An empty mode is fixed rate.
+func (t IntervalTrigger) validate() error {
+	if t.Period <= 0 {
+		return errorx.IllegalArgument.New("period must be positive")
+	}
+	if t.Mode != "" && t.Mode != IntervalModeFixedRate && t.Mode != IntervalModeFixedDelay {
+		return errorx.IllegalArgument.New("invalid interval mode: %s", t.Mode)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package pkg
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// JitterOffset() returns how far the task definition's fire times are shifted, a pseudo random duration less than
+// Jitter derived from the id so that every replica computes the same offset. The offset is constant for a task
+// definition, so its fire times keep the trigger's spacing.
+func (t TaskDefinition) JitterOffset() time.Duration {
+	if t.Jitter <= 0 || t.Id == nil {
+		return 0
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(t.Id.String()))
+	// stores keep times to the microsecond, a finer offset wouldn't survive a round trip and the stored next fire time
+	// would map back to the fire time before it
+	return time.Duration(hash.Sum64() % uint64(t.Jitter)).Truncate(time.Millisecond)
+}
+
+// getJitteredFireTime() returns the trigger's first fire time after from, shifted by the jitter offset
+func (t TaskDefinition) getJitteredFireTime(from time.Time) *time.Time {
+	trigger := t.GetTrigger()
+	if trigger == nil {
+		// task definitions with dependencies fire when their dependencies complete
+		return nil
+	}
+	offset := t.JitterOffset()
+	fireTime := trigger.GetFireTime(from.Add(-offset))
+	if fireTime == nil || offset == 0 {
+		return fireTime
+	}
+	jittered := fireTime.Add(offset)
+	return &jittered
+}
@@ -0,0 +1,93 @@
+package pkg
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+
+	"github.com/joomcode/errorx"
+)
+
+// KeyProviderInterface wraps and unwraps the data keys that task definition payloads are encrypted with. Providers
+// keep the keys they've wrapped data keys with, so that payloads encrypted before a rotation can still be decrypted.
+type KeyProviderInterface interface {
+	// CurrentKeyId() returns the id of the key that new data keys are wrapped with
+	CurrentKeyId() string
+	// WrapKey() encrypts the data key with the current key, and returns the current key's id
+	WrapKey(dataKey []byte) (keyId string, wrappedKey []byte, err error)
+	// UnwrapKey() decrypts a data key that was wrapped with the key with the id. Return a DecryptionFailed error when
+	// the key is unknown or the data key doesn't decrypt, other errors are treated as transient and retried.
+	UnwrapKey(keyId string, wrappedKey []byte) ([]byte, error)
+}
+
+// StaticKeyProvider wraps data keys with AES-GCM, using keys held in memory. To rotate keys add a new key, make it the
+// current key, and call RewrapPayloadKeys() before removing the old one.
+type StaticKeyProvider struct {
+	currentKeyId string
+	keys         map[string]cipher.AEAD
+}
+
+// NewStaticKeyProvider() returns a key provider with the keys, keyed by their ids. Keys must be 16, 24 or 32 bytes,
+// for AES-128, AES-192 or AES-256.
+func NewStaticKeyProvider(currentKeyId string, keys map[string][]byte) (*StaticKeyProvider, error) {
+	if _, ok := keys[currentKeyId]; !ok {
+		return nil, errorx.IllegalArgument.New("current key %s isn't one of the keys", currentKeyId)
+	}
+	provider := &StaticKeyProvider{currentKeyId: currentKeyId, keys: map[string]cipher.AEAD{}}
+	for keyId, key := range keys {
+		aead, err := newAead(key)
+		if err != nil {
+			return nil, errorx.IllegalArgument.Wrap(err, "invalid key %s", keyId)
+		}
+		provider.keys[keyId] = aead
+	}
+	return provider, nil
+}
+
+func (p *StaticKeyProvider) CurrentKeyId() string {
+	return p.currentKeyId
+}
+
+func (p *StaticKeyProvider) WrapKey(dataKey []byte) (string, []byte, error) {
+	wrappedKey, err := seal(p.keys[p.currentKeyId], dataKey, nil)
+	return p.currentKeyId, wrappedKey, err
+}
+
+func (p *StaticKeyProvider) UnwrapKey(keyId string, wrappedKey []byte) ([]byte, error) {
+	aead, ok := p.keys[keyId]
+	if !ok {
+		return nil, DecryptionFailed.New("unknown key %s", keyId)
+	}
+	return open(aead, wrappedKey, nil)
+}
+
+func newAead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal() encrypts the plaintext with a random nonce, which is prepended to the ciphertext
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, DecryptionFailed.New("ciphertext is too short")
+	}
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, DecryptionFailed.Wrap(err, "error decrypting ciphertext")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"time"
+
+	"github.com/catalystsquad/app-utils-go/logging"
+	"github.com/sirupsen/logrus"
+)
+
+const leaderLeaseName = "scheduler_leader"
+
+// IsLeader() returns true if this node is the elected leader. When leader election is disabled every node is the leader.
+func (s *Scheduler) IsLeader() bool {
+	if !s.leaderElection {
+		return true
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return time.Now().Before(s.leaderLeaseExpires)
+}
+
+func (s *Scheduler) startLeaderElection() {
+	// campaign immediately so a new cluster doesn't wait a full tick for a leader
+	s.campaign()
+	ticker := time.NewTicker(s.leaseDuration / 3)
+	for range ticker.C {
+		if s.run {
+			s.campaign()
+		} else {
+			ticker.Stop()
+			break
+		}
+	}
+}
+
+// campaign() acquires the leader lease, or renews it if this node already holds it. The local lease expiration is
+// computed from before the store call so that this node always steps down before any other node can take over.
+func (s *Scheduler) campaign() {
+	attemptedAt := time.Now()
+	acquired, err := s.store.AcquireLease(leaderLeaseName, &s.nodeId, s.leaseDuration)
+	if err != nil {
+		logging.Log.WithError(err).WithFields(logrus.Fields{"node_id": s.nodeId}).Error("error acquiring leader lease")
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	wasLeader := time.Now().Before(s.leaderLeaseExpires)
+	if acquired {
+		s.leaderLeaseExpires = attemptedAt.Add(s.leaseDuration)
+	} else {
+		s.leaderLeaseExpires = time.Time{}
+	}
+	if acquired != wasLeader {
+		logging.Log.WithFields(logrus.Fields{"node_id": s.nodeId, "leader": acquired}).Info("leadership changed")
+	}
+}
+
+// resign() releases the leader lease so another node can take over without waiting for the lease to lapse
+func (s *Scheduler) resign() {
+	s.lock.Lock()
+	s.leaderLeaseExpires = time.Time{}
+	s.lock.Unlock()
+	err := s.store.ReleaseLease(leaderLeaseName, &s.nodeId)
+	if err != nil {
+		logging.Log.WithError(err).WithFields(logrus.Fields{"node_id": s.nodeId}).Error("error releasing leader lease")
+	}
+}
@@ -0,0 +1,82 @@
+package pkg
+
+import (
+	"time"
+)
+
+const defaultMaxMisfires = 100
+
+// MisfirePolicy controls what the scheduler does when a recurring task definition's next fire time was missed, which
+// happens when the scheduler was down, or couldn't keep up, for longer than the schedule window
+type MisfirePolicy string
+
+const (
+	// MisfirePolicySkip drops the missed fire times and skips ahead to the next fire time, this is the default
+	MisfirePolicySkip MisfirePolicy = "skip"
+	// MisfirePolicyFireOnce creates a single task instance that executes now for all the missed fire times
+	MisfirePolicyFireOnce MisfirePolicy = "fire_once"
+	// MisfirePolicyFireAll creates a task instance for every missed fire time, up to the max misfires
+	MisfirePolicyFireAll MisfirePolicy = "fire_all"
+)
+
+func (p MisfirePolicy) IsValid() bool {
+	switch p {
+	case "", MisfirePolicySkip, MisfirePolicyFireOnce, MisfirePolicyFireAll:
+		return true
+	}
+	return false
+}
+
+// WithMaxMisfires() sets the default cap on catch up task instances for task definitions with MisfirePolicyFireAll,
+// defaults to 100
+func WithMaxMisfires(maxMisfires int) SchedulerOpt {
+	return func(s *Scheduler) {
+		s.maxMisfires = maxMisfires
+	}
+}
+
+// dueFireTimes() returns the fire times to create task instances for, and the task definition's next fire time
+// afterwards. Fire times of recurring task definitions are computed from the stored next fire time, which is considered
+// missed when it's more than a schedule window in the past.
+func (s *Scheduler) dueFireTimes(taskDefinition TaskDefinition, now time.Time) ([]time.Time, *time.Time) {
+	if !taskDefinition.Recurring || taskDefinition.NextFireTime == nil {
+		// non recurring triggers fire once, no matter how late. Bounds, calendars and max occurrences can leave no fire
+		// time at all.
+		nextFireTime := taskDefinition.GetNextFireTime()
+		if nextFireTime == nil {
+			return nil, nil
+		}
+		return []time.Time{*nextFireTime}, nil
+	}
+	fireTime := *taskDefinition.NextFireTime
+	if firesAfterCompletion(taskDefinition.GetTrigger()) {
+		// fire once no matter how late, the next fire time is set when the task instance completes
+		return []time.Time{fireTime}, nil
+	}
+	if !fireTime.Before(now.Add(-*s.ScheduleWindow)) {
+		return []time.Time{fireTime}, taskDefinition.GetFireTimeFrom(fireTime)
+	}
+	switch taskDefinition.MisfirePolicy {
+	case MisfirePolicyFireAll:
+		maxMisfires := taskDefinition.MaxMisfires
+		if maxMisfires == 0 {
+			maxMisfires = s.maxMisfires
+		}
+		fireTimes := []time.Time{}
+		nextFireTime := &fireTime
+		// triggers return nil once they're exhausted
+		for nextFireTime != nil && !nextFireTime.After(now) && len(fireTimes) < maxMisfires {
+			fireTimes = append(fireTimes, *nextFireTime)
+			nextFireTime = taskDefinition.GetFireTimeFrom(*nextFireTime)
+		}
+		if nextFireTime != nil && nextFireTime.Before(now) {
+			// capped, drop the rest of the missed fire times
+			nextFireTime = taskDefinition.GetFireTimeFrom(now)
+		}
+		return fireTimes, nextFireTime
+	case MisfirePolicyFireOnce:
+		return []time.Time{now}, taskDefinition.GetFireTimeFrom(now)
+	default:
+		return nil, taskDefinition.GetFireTimeFrom(now)
+	}
+}
@@ -0,0 +1,106 @@
+package pkg
+
+import (
+	"github.com/joomcode/errorx"
+)
+
+const namespacePageSize = 100
+
+// WithNamespaceHandler() routes the task instances of task definitions in the namespace to the handler, instead of the
+// scheduler's handler
+func WithNamespaceHandler(namespace string, handler func(taskInstance TaskInstance) error) SchedulerOpt {
+	return func(s *Scheduler) {
+		s.namespaceHandlers[namespace] = handler
+	}
+}
+
+// WithNamespaceConcurrency() caps the number of task instances of task definitions in the namespace that this node
+// runs at once, task instances over the cap wait for a running one to finish. The cap must be positive.
+func WithNamespaceConcurrency(namespace string, maxConcurrent int) SchedulerOpt {
+	return func(s *Scheduler) {
+		s.namespaceConcurrency[namespace] = maxConcurrent
+	}
+}
+
+// initializeNamespaceSlots() creates the semaphores for namespaces with a concurrency cap
+func (s *Scheduler) initializeNamespaceSlots() error {
+	for namespace, maxConcurrent := range s.namespaceConcurrency {
+		if maxConcurrent <= 0 {
+			return errorx.IllegalArgument.New("concurrency cap of namespace %s must be positive", namespace)
+		}
+		s.namespaceSlots[namespace] = make(chan struct{}, maxConcurrent)
+	}
+	return nil
+}
+
+// getHandler() returns the handler for the task instance's payload type or namespace
+func (s *Scheduler) getHandler(taskInstance TaskInstance) func(taskInstance TaskInstance) error {
+	if handler, ok := s.typedHandlers[taskInstance.TaskDefinition.PayloadType]; ok && taskInstance.TaskDefinition.PayloadType != "" {
+		return handler
+	}
+	if handler, ok := s.namespaceHandlers[taskInstance.TaskDefinition.Namespace]; ok {
+		return handler
+	}
+	return s.Handler
+}
+
+// acquireNamespaceSlot() blocks until the task instance's namespace is under its concurrency cap, and returns a
+// function that releases the slot
+func (s *Scheduler) acquireNamespaceSlot(taskInstance TaskInstance) func() {
+	slots, ok := s.namespaceSlots[taskInstance.TaskDefinition.Namespace]
+	if !ok {
+		return func() {}
+	}
+	slots <- struct{}{}
+	return func() {
+		<-slots
+	}
+}
+
+func (s *Scheduler) ListNamespaceTaskDefinitions(namespace string, offset, limit int) ([]TaskDefinition, error) {
+	return s.store.ListTaskDefinitionsInNamespace(namespace, offset, limit)
+}
+
+func (s *Scheduler) CountNamespaceTaskDefinitions(namespace string) (int64, error) {
+	return s.store.CountTaskDefinitionsInNamespace(namespace)
+}
+
+// PauseNamespace() pauses every task definition in the namespace, see PauseTaskDefinition()
+func (s *Scheduler) PauseNamespace(namespace string) error {
+	if namespace == "" {
+		return errorx.IllegalArgument.New("a namespace must be provided")
+	}
+	return s.store.PauseTaskDefinitionsInNamespace(namespace)
+}
+
+// ResumeNamespace() resumes every paused task definition in the namespace, see ResumeTaskDefinition()
+func (s *Scheduler) ResumeNamespace(namespace string, behavior ResumeBehavior) error {
+	if namespace == "" {
+		return errorx.IllegalArgument.New("a namespace must be provided")
+	}
+	for offset := 0; ; offset += namespacePageSize {
+		definitions, err := s.store.ListTaskDefinitionsInNamespace(namespace, offset, namespacePageSize)
+		if err != nil {
+			return err
+		}
+		for _, definition := range definitions {
+			if definition.Paused {
+				err = s.ResumeTaskDefinition(definition.Id, behavior)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if len(definitions) < namespacePageSize {
+			return nil
+		}
+	}
+}
+
+// DeleteNamespace() deletes every task definition in the namespace, and their task instances
+func (s *Scheduler) DeleteNamespace(namespace string) error {
+	if namespace == "" {
+		return errorx.IllegalArgument.New("a namespace must be provided")
+	}
+	return s.store.DeleteTaskDefinitionsInNamespace(namespace)
+}
@@ -0,0 +1,719 @@
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joomcode/errorx"
+)
+
+const (
+	unitMinute  = "minute"
+	unitHour    = "hour"
+	unitDay     = "day"
+	unitWeekday = "weekday"
+	unitWeek    = "week"
+	unitMonth   = "month"
+
+	normalizedDateFormat = "2006-01-02"
+	normalizedTimeFormat = "15:04"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "sundays": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mondays": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tuesdays": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wednesdays": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thursdays": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fridays": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "saturdays": time.Saturday, "sat": time.Saturday,
+}
+
+var monthNames = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var numberWords = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+var ordinalWords = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "last": -1,
+}
+
+// maxEvery caps `every` for each unit at about a year, which keeps periods from overflowing a time.Duration and bounds
+// the search for the next fire time
+var maxEvery = map[string]int{
+	unitMinute:  366 * 24 * 60,
+	unitHour:    366 * 24,
+	unitDay:     366,
+	unitWeekday: 262,
+	unitWeek:    53,
+	unitMonth:   12,
+}
+
+// naturalLanguageSchedule is the parsed form of a natural language trigger. Either once is set, or the schedule
+// recurs every `every` units.
+type naturalLanguageSchedule struct {
+	once  *time.Time
+	every int
+	unit  string
+	// unitWeek, the days of the week to fire on
+	weekdays []time.Weekday
+	// unitMonth, either the day of the month to fire on with -1 for the last day, or the nth weekday of the month with
+	// -1 for the last
+	monthDay int
+	ordinal  int
+	weekday  time.Weekday
+	// the time of day to fire at, for units of a day or longer
+	hour   int
+	minute int
+	// the instant the schedule starts for units shorter than a day, otherwise the date it starts, in the location
+	start time.Time
+}
+
+type naturalLanguageParser struct {
+	text     string
+	tokens   []string
+	position int
+	now      time.Time
+	location *time.Location
+}
+
+// parseNaturalLanguage() parses English phrases like "every other tuesday at 9:34am", "the last friday of the month",
+// "in 3 hours", or "tomorrow at noon". Relative phrases are resolved against now, in the location.
+func parseNaturalLanguage(text string, now time.Time, location *time.Location) (naturalLanguageSchedule, error) {
+	parser := &naturalLanguageParser{text: text, tokens: tokenize(text), now: now.In(location), location: location}
+	schedule, err := parser.parse()
+	if err != nil {
+		return schedule, err
+	}
+	if !parser.done() {
+		return schedule, parser.errorf("unexpected %q", parser.peek())
+	}
+	return schedule, nil
+}
+
+func tokenize(text string) []string {
+	text = strings.ToLower(text)
+	text = strings.NewReplacer(",", " ", ".", " ").Replace(text)
+	fields := strings.Fields(text)
+	tokens := []string{}
+	for i, field := range fields {
+		// join "9 am" and "9:34 pm" into a single token
+		if (field == "am" || field == "pm") && i > 0 && len(tokens) > 0 && isClockNumber(tokens[len(tokens)-1]) {
+			tokens[len(tokens)-1] += field
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+func isClockNumber(token string) bool {
+	for _, r := range token {
+		if (r < '0' || r > '9') && r != ':' {
+			return false
+		}
+	}
+	return token != ""
+}
+
+func (p *naturalLanguageParser) errorf(format string, args ...interface{}) error {
+	return errorx.IllegalArgument.New("could not understand %q: %s", p.text, fmt.Sprintf(format, args...))
+}
+
+func (p *naturalLanguageParser) done() bool {
+	return p.position >= len(p.tokens)
+}
+
+func (p *naturalLanguageParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.position]
+}
+
+func (p *naturalLanguageParser) next() string {
+	token := p.peek()
+	p.position++
+	return token
+}
+
+func (p *naturalLanguageParser) accept(words ...string) bool {
+	for _, word := range words {
+		if p.peek() == word {
+			p.position++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *naturalLanguageParser) parse() (naturalLanguageSchedule, error) {
+	schedule := naturalLanguageSchedule{}
+	if p.done() {
+		return schedule, p.errorf("it's empty")
+	}
+	token := p.peek()
+	switch {
+	case p.accept("once"):
+		return p.parseOnceAt()
+	case p.accept("in"):
+		return p.parseIn()
+	case p.accept("every"):
+		return p.parseEvery()
+	case p.accept("daily"):
+		schedule.every = 1
+		schedule.unit = unitDay
+		return schedule, p.parseRecurringClauses(&schedule)
+	case token == "hourly" || token == "weekly" || token == "monthly":
+		return schedule, p.errorf("%q doesn't say when it fires, try something like \"every hour\", \"every week on monday\", or \"the 1st of every month\"", token)
+	case token == "the":
+		return p.parseMonthly()
+	case token == "next":
+		return schedule, p.errorf("\"next\" is ambiguous, use \"on tuesday\" for the coming tuesday or give a date like \"on 2026-11-03\"")
+	case token == "on" || token == "this" || token == "today" || token == "tomorrow" || token == "at":
+		return p.parseOnce()
+	}
+	if _, ok := weekdayNames[token]; ok {
+		return p.parseOnce()
+	}
+	return schedule, p.errorf("unexpected %q, phrases start with \"every\", \"in\", \"on\", \"at\", \"the\", \"today\", or \"tomorrow\"", token)
+}
+
+// parseOnceAt() parses the normalized form of one time schedules, "once at <RFC3339 time>"
+func (p *naturalLanguageParser) parseOnceAt() (naturalLanguageSchedule, error) {
+	schedule := naturalLanguageSchedule{}
+	if !p.accept("at") {
+		return schedule, p.errorf("expected \"at\" after \"once\"")
+	}
+	token := p.next()
+	once, err := time.Parse(time.RFC3339, strings.ToUpper(token))
+	if err != nil {
+		return schedule, p.errorf("%q isn't an RFC3339 time", token)
+	}
+	schedule.once = &once
+	return schedule, nil
+}
+
+// parseIn() parses "in 3 hours", "in an hour", "in 2 days"
+func (p *naturalLanguageParser) parseIn() (naturalLanguageSchedule, error) {
+	schedule := naturalLanguageSchedule{}
+	amount, ok := p.parseNumber()
+	if !ok {
+		return schedule, p.errorf("expected a number after \"in\", like \"in 3 hours\"")
+	}
+	base := p.now.Truncate(time.Second)
+	var once time.Time
+	switch unit := p.next(); unit {
+	case "minute", "minutes", "min", "mins":
+		once = base.Add(time.Duration(amount) * time.Minute)
+	case "hour", "hours":
+		once = base.Add(time.Duration(amount) * time.Hour)
+	case "day", "days":
+		once = base.AddDate(0, 0, amount)
+	case "week", "weeks":
+		once = base.AddDate(0, 0, 7*amount)
+	case "month", "months":
+		once = base.AddDate(0, amount, 0)
+	default:
+		return schedule, p.errorf("unknown unit %q, use minutes, hours, days, weeks, or months", unit)
+	}
+	schedule.once = &once
+	return schedule, nil
+}
+
+// parseOnce() parses a date with an optional time like "tomorrow at noon", "on tuesday at 9am", "on november 3 at
+// 5pm", "today at 17:00", or just a time like "at 5pm"
+func (p *naturalLanguageParser) parseOnce() (naturalLanguageSchedule, error) {
+	schedule := naturalLanguageSchedule{}
+	today := dateOf(p.now)
+	var date *time.Time
+	relative := ""
+	switch {
+	case p.accept("today"):
+		date = &today
+		relative = "today"
+	case p.accept("tomorrow"):
+		tomorrow := today.AddDate(0, 0, 1)
+		date = &tomorrow
+		relative = "tomorrow"
+	case p.peek() == "at":
+		// a time on its own is the next time the clock reads it
+	default:
+		p.accept("on")
+		p.accept("this")
+		parsed, err := p.parseDate()
+		if err != nil {
+			return schedule, err
+		}
+		date = parsed
+	}
+	hour, minute := 0, 0
+	hasTime := false
+	if p.accept("at") {
+		var err error
+		hour, minute, err = p.parseTimeOfDay()
+		if err != nil {
+			return schedule, err
+		}
+		hasTime = true
+	}
+	if date == nil {
+		// today if the time hasn't passed yet, otherwise tomorrow
+		once := fromWallClock(today.Add(time.Duration(hour)*time.Hour+time.Duration(minute)*time.Minute), p.location)
+		if !once.After(p.now) {
+			once = fromWallClock(today.AddDate(0, 0, 1).Add(time.Duration(hour)*time.Hour+time.Duration(minute)*time.Minute), p.location)
+		}
+		schedule.once = &once
+		return schedule, nil
+	}
+	once := fromWallClock(date.Add(time.Duration(hour)*time.Hour+time.Duration(minute)*time.Minute), p.location)
+	if !once.After(p.now) {
+		if relative != "" && hasTime {
+			return schedule, p.errorf("%s at %02d:%02d has already passed", relative, hour, minute)
+		}
+		if relative != "" {
+			return schedule, p.errorf("%s has already started, give it a time like \"%s at 5pm\"", relative, relative)
+		}
+		return schedule, p.errorf("%s is in the past", once.Format(time.RFC3339))
+	}
+	schedule.once = &once
+	return schedule, nil
+}
+
+// parseDate() parses a weekday, an ISO date, or a month and day with an optional year. Weekdays and dates without a
+// year are the next time they occur, including today.
+func (p *naturalLanguageParser) parseDate() (*time.Time, error) {
+	today := dateOf(p.now)
+	token := p.next()
+	if weekday, ok := weekdayNames[token]; ok {
+		date := today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7)
+		return &date, nil
+	}
+	if strings.Contains(token, "/") {
+		return nil, p.errorf("%q is ambiguous, it could be month/day or day/month, use a date like 2026-11-03 or november 3", token)
+	}
+	if date, err := time.Parse(normalizedDateFormat, token); err == nil {
+		return &date, nil
+	}
+	month, day := time.Month(0), 0
+	if parsedMonth, ok := monthNames[token]; ok {
+		// november 3
+		month = parsedMonth
+		day, ok = parseDayOfMonth(p.next())
+		if !ok {
+			return nil, p.errorf("expected a day after %q", token)
+		}
+	} else if parsedDay, ok := parseDayOfMonth(token); ok {
+		// 3 november, or the 3rd of november
+		day = parsedDay
+		p.accept("of")
+		month, ok = monthNames[p.next()]
+		if !ok {
+			return nil, p.errorf("expected a month after %q", token)
+		}
+	} else {
+		return nil, p.errorf("unexpected %q, expected a date like \"tuesday\", \"2026-11-03\", or \"november 3\"", token)
+	}
+	year := today.Year()
+	if yearToken := p.peek(); len(yearToken) == 4 {
+		if parsedYear, err := strconv.Atoi(yearToken); err == nil {
+			p.next()
+			year = parsedYear
+		}
+	} else if time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Before(today) {
+		year++
+	}
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return nil, p.errorf("%s doesn't have a day %d", month, day)
+	}
+	return &date, nil
+}
+
+// parseEvery() parses the recurring schedules that follow "every"
+func (p *naturalLanguageParser) parseEvery() (naturalLanguageSchedule, error) {
+	schedule := naturalLanguageSchedule{every: 1}
+	if p.accept("other") {
+		schedule.every = 2
+	} else if number, ok := p.parseNumber(); ok {
+		schedule.every = number
+	}
+	token := p.next()
+	switch token {
+	case "minute", "minutes", "min", "mins":
+		schedule.unit = unitMinute
+	case "hour", "hours":
+		schedule.unit = unitHour
+	case "day", "days":
+		schedule.unit = unitDay
+	case "weekday", "weekdays":
+		schedule.unit = unitWeekday
+	case "weekend", "weekends":
+		schedule.unit = unitWeek
+		schedule.weekdays = []time.Weekday{time.Saturday, time.Sunday}
+	case "week", "weeks":
+		schedule.unit = unitWeek
+		if !p.accept("on") {
+			return schedule, p.errorf("\"every week\" doesn't say which day, try \"every week on monday\"")
+		}
+		weekdays, err := p.parseWeekdays()
+		if err != nil {
+			return schedule, err
+		}
+		schedule.weekdays = weekdays
+	case "month", "months":
+		schedule.unit = unitMonth
+		if !p.accept("on") {
+			return schedule, p.errorf("\"every month\" doesn't say which day, try \"every month on the 1st\" or \"every month on the last friday\"")
+		}
+		err := p.parseMonthDay(&schedule)
+		if err != nil {
+			return schedule, err
+		}
+	case "":
+		return schedule, p.errorf("\"every\" needs a unit, like \"every day\" or \"every tuesday\"")
+	default:
+		if _, ok := weekdayNames[token]; !ok {
+			return schedule, p.errorf("unknown unit %q, use minutes, hours, days, weekdays, weeks, months, or a day of the week", token)
+		}
+		p.position--
+		schedule.unit = unitWeek
+		weekdays, err := p.parseWeekdays()
+		if err != nil {
+			return schedule, err
+		}
+		schedule.weekdays = weekdays
+	}
+	err := p.checkEvery(schedule)
+	if err != nil {
+		return schedule, err
+	}
+	return schedule, p.parseRecurringClauses(&schedule)
+}
+
+// checkEvery() rejects intervals under one unit, or over about a year
+func (p *naturalLanguageParser) checkEvery(schedule naturalLanguageSchedule) error {
+	if schedule.every < 1 {
+		return p.errorf("every must be at least 1")
+	}
+	if schedule.every > maxEvery[schedule.unit] {
+		return p.errorf("every %d %ss is too far apart, the most is %d %ss", schedule.every, schedule.unit, maxEvery[schedule.unit], schedule.unit)
+	}
+	return nil
+}
+
+// parseMonthly() parses "the last friday of the month", "the 15th of every other month"
+func (p *naturalLanguageParser) parseMonthly() (naturalLanguageSchedule, error) {
+	schedule := naturalLanguageSchedule{every: 1, unit: unitMonth}
+	err := p.parseMonthDay(&schedule)
+	if err != nil {
+		return schedule, err
+	}
+	if !p.accept("of") {
+		return schedule, p.errorf("expected \"of every month\" or \"of the month\", a single date looks like \"on november 3\"")
+	}
+	if !p.accept("the", "every", "each") {
+		return schedule, p.errorf("expected \"the month\" or \"every month\" after \"of\"")
+	}
+	if p.accept("other") {
+		schedule.every = 2
+	} else if number, ok := p.parseNumber(); ok {
+		schedule.every = number
+	}
+	if !p.accept("month", "months") {
+		return schedule, p.errorf("expected \"month\", for a single date use something like \"on november 3\"")
+	}
+	err = p.checkEvery(schedule)
+	if err != nil {
+		return schedule, err
+	}
+	return schedule, p.parseRecurringClauses(&schedule)
+}
+
+// parseMonthDay() parses "the 15th", "day 15", "the last day", "the first monday", "the last friday"
+func (p *naturalLanguageParser) parseMonthDay(schedule *naturalLanguageSchedule) error {
+	p.accept("the")
+	if p.accept("day") {
+		day, ok := parseDayOfMonth(p.next())
+		if !ok {
+			return p.errorf("expected a day of the month after \"day\"")
+		}
+		schedule.monthDay = day
+		return nil
+	}
+	token := p.next()
+	ordinal, isOrdinal := ordinalWords[token]
+	if !isOrdinal {
+		if day, ok := parseDayOfMonth(token); ok && !p.peekWeekday() {
+			schedule.monthDay = day
+			return nil
+		}
+		ordinal, isOrdinal = parseOrdinal(token)
+	}
+	if !isOrdinal {
+		return p.errorf("unexpected %q, expected a day of the month like \"the 15th\", \"the last day\", or \"the first monday\"", token)
+	}
+	if p.accept("day") {
+		if ordinal != -1 {
+			return p.errorf("use \"the %s\" for a day of the month", token)
+		}
+		schedule.monthDay = -1
+		return nil
+	}
+	weekday, ok := weekdayNames[p.next()]
+	if !ok {
+		return p.errorf("expected a day of the week after %q", token)
+	}
+	if ordinal > 5 {
+		return p.errorf("a month has at most 5 of each weekday")
+	}
+	schedule.ordinal = ordinal
+	schedule.weekday = weekday
+	return nil
+}
+
+func (p *naturalLanguageParser) peekWeekday() bool {
+	_, ok := weekdayNames[p.peek()]
+	return ok
+}
+
+// parseWeekdays() parses "monday", "monday and thursday", "mon wed fri"
+func (p *naturalLanguageParser) parseWeekdays() ([]time.Weekday, error) {
+	weekdays := []time.Weekday{}
+	seen := map[time.Weekday]bool{}
+	for {
+		weekday, ok := weekdayNames[p.peek()]
+		if !ok {
+			break
+		}
+		p.next()
+		if !seen[weekday] {
+			seen[weekday] = true
+			weekdays = append(weekdays, weekday)
+		}
+		p.accept("and")
+	}
+	if len(weekdays) == 0 {
+		return nil, p.errorf("expected a day of the week, found %q", p.peek())
+	}
+	sortWeekdays(weekdays)
+	return weekdays, nil
+}
+
+// parseRecurringClauses() parses the optional "at <time>" and "starting <date>" clauses of recurring schedules
+func (p *naturalLanguageParser) parseRecurringClauses(schedule *naturalLanguageSchedule) error {
+	hasStart := false
+	for !p.done() {
+		switch {
+		case p.accept("at"):
+			if schedule.unit == unitMinute || schedule.unit == unitHour {
+				return p.errorf("\"at\" doesn't apply to schedules shorter than a day, use \"starting\" with a time instead")
+			}
+			hour, minute, err := p.parseTimeOfDay()
+			if err != nil {
+				return err
+			}
+			schedule.hour = hour
+			schedule.minute = minute
+		case p.accept("starting", "from"):
+			start, err := p.parseStart(schedule.unit)
+			if err != nil {
+				return err
+			}
+			schedule.start = start
+			hasStart = true
+		default:
+			return p.errorf("unexpected %q, expected \"at\" or \"starting\"", p.peek())
+		}
+	}
+	if !hasStart {
+		if schedule.unit == unitMinute || schedule.unit == unitHour {
+			schedule.start = p.now.Truncate(time.Second)
+		} else {
+			schedule.start = dateOf(p.now)
+		}
+	}
+	return nil
+}
+
+func (p *naturalLanguageParser) parseStart(unit string) (time.Time, error) {
+	token := p.next()
+	if unit == unitMinute || unit == unitHour {
+		start, err := time.Parse(time.RFC3339, strings.ToUpper(token))
+		if err != nil {
+			return start, p.errorf("%q isn't an RFC3339 time", token)
+		}
+		return start, nil
+	}
+	if token == "today" {
+		return dateOf(p.now), nil
+	}
+	if token == "tomorrow" {
+		return dateOf(p.now).AddDate(0, 0, 1), nil
+	}
+	start, err := time.Parse(normalizedDateFormat, token)
+	if err != nil {
+		return start, p.errorf("%q isn't a date like 2026-11-03", token)
+	}
+	return start, nil
+}
+
+// parseTimeOfDay() parses "noon", "midnight", "9am", "9:34pm", "17:00", "09:00". Hours from 1 to 12 without am or
+// pm are rejected unless they're written as a 24 hour time, since "at 9" could mean 9am or 9pm.
+func (p *naturalLanguageParser) parseTimeOfDay() (int, int, error) {
+	token := p.next()
+	switch token {
+	case "noon":
+		return 12, 0, nil
+	case "midnight":
+		return 0, 0, nil
+	case "":
+		return 0, 0, p.errorf("expected a time after \"at\"")
+	}
+	meridiem := ""
+	if strings.HasSuffix(token, "am") || strings.HasSuffix(token, "pm") {
+		meridiem = token[len(token)-2:]
+		token = token[:len(token)-2]
+	}
+	hourToken, minuteToken, hasMinutes := strings.Cut(token, ":")
+	hour, err := strconv.Atoi(hourToken)
+	if err != nil {
+		return 0, 0, p.errorf("%q isn't a time, use something like 9am, 9:34pm, or 17:00", token+meridiem)
+	}
+	minute := 0
+	if hasMinutes {
+		minute, err = strconv.Atoi(minuteToken)
+		if err != nil || len(minuteToken) != 2 || minute > 59 {
+			return 0, 0, p.errorf("%q isn't a time, use something like 9am, 9:34pm, or 17:00", token+meridiem)
+		}
+	}
+	if meridiem != "" {
+		if hour < 1 || hour > 12 {
+			return 0, 0, p.errorf("%q isn't a time, hours go from 1 to 12 with am or pm", token+meridiem)
+		}
+		hour = hour % 12
+		if meridiem == "pm" {
+			hour += 12
+		}
+		return hour, minute, nil
+	}
+	if hour > 23 {
+		return 0, 0, p.errorf("%q isn't a time, hours go from 0 to 23", token)
+	}
+	// 24 hour times need minutes and two digit hours, "9", "9:30" and "10" could be morning or evening
+	if hour >= 1 && hour <= 12 && (!hasMinutes || len(hourToken) == 1) {
+		return 0, 0, p.errorf("\"at %s\" is ambiguous, did you mean %sam or %spm? Use am or pm, or a 24 hour time like %02d:%02d", token, token, token, hour, minute)
+	}
+	return hour, minute, nil
+}
+
+func (p *naturalLanguageParser) parseNumber() (int, bool) {
+	token := p.peek()
+	if number, ok := numberWords[token]; ok {
+		p.next()
+		return number, true
+	}
+	number, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, false
+	}
+	p.next()
+	return number, true
+}
+
+// parseDayOfMonth() parses "15", "15th", "1st", "22nd", "3rd"
+func parseDayOfMonth(token string) (int, bool) {
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		token = strings.TrimSuffix(token, suffix)
+	}
+	day, err := strconv.Atoi(token)
+	if err != nil || day < 1 || day > 31 {
+		return 0, false
+	}
+	return day, true
+}
+
+// parseOrdinal() parses "1st" through "5th"
+func parseOrdinal(token string) (int, bool) {
+	day, ok := parseDayOfMonth(token)
+	if !ok || token == strconv.Itoa(day) {
+		return 0, false
+	}
+	return day, true
+}
+
+func sortWeekdays(weekdays []time.Weekday) {
+	// monday first, sunday last
+	key := func(weekday time.Weekday) int { return (int(weekday) + 6) % 7 }
+	for i := 1; i < len(weekdays); i++ {
+		for j := i; j > 0 && key(weekdays[j]) < key(weekdays[j-1]); j-- {
+			weekdays[j], weekdays[j-1] = weekdays[j-1], weekdays[j]
+		}
+	}
+}
+
+// dateOf() returns the date of t's wall clock as midnight UTC, so dates can be compared and stepped without daylight
+// saving transitions
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// normalize() returns the canonical text of the schedule, which parses back to the same schedule without depending on
+// when it's parsed
+func (s naturalLanguageSchedule) normalize(location *time.Location) string {
+	if s.once != nil {
+		return "once at " + s.once.In(location).Format(time.RFC3339)
+	}
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("every %d %ss", s.every, s.unit))
+	switch s.unit {
+	case unitMinute, unitHour:
+		builder.WriteString(" starting " + s.start.In(location).Format(time.RFC3339))
+		return builder.String()
+	case unitWeek:
+		names := []string{}
+		for _, weekday := range s.weekdays {
+			names = append(names, strings.ToLower(weekday.String()))
+		}
+		builder.WriteString(" on " + strings.Join(names, " and "))
+	case unitMonth:
+		switch {
+		case s.monthDay == -1:
+			builder.WriteString(" on the last day")
+		case s.monthDay > 0:
+			builder.WriteString(fmt.Sprintf(" on day %d", s.monthDay))
+		case s.ordinal == -1:
+			builder.WriteString(" on the last " + strings.ToLower(s.weekday.String()))
+		default:
+			builder.WriteString(fmt.Sprintf(" on the %s %s", ordinalName(s.ordinal), strings.ToLower(s.weekday.String())))
+		}
+	}
+	builder.WriteString(fmt.Sprintf(" at %02d:%02d starting %s", s.hour, s.minute, s.start.Format(normalizedDateFormat)))
+	return builder.String()
+}
+
+func ordinalName(ordinal int) string {
+	for name, value := range ordinalWords {
+		if value == ordinal {
+			return name
+		}
+	}
+	return strconv.Itoa(ordinal)
+}
@@ -0,0 +1,216 @@
+package pkg
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/joomcode/errorx"
+)
+
+// NaturalLanguageTrigger fires on a schedule described in English, like "every other tuesday at 9:34am", "every
+// weekday at 17:00", "the last friday of the month", "in 3 hours", or "tomorrow at noon". Relative phrases are resolved
+// when the trigger is created, Normalized holds the schedule in a form that doesn't depend on when it's parsed.
+type NaturalLanguageTrigger struct {
+	// Text is the phrase the trigger was created from
+	Text       string `json:"text"`
+	Normalized string `json:"normalized"`
+	// Location is the IANA time zone name the schedule is evaluated in, UTC if empty
+	Location string `json:"location"`
+	schedule *naturalLanguageSchedule
+	location *time.Location
+}
+
+func (t NaturalLanguageTrigger) GetFireTime(from time.Time) *time.Time {
+	schedule, location := t.getSchedule()
+	if schedule == nil {
+		return nil
+	}
+	if schedule.once != nil {
+		return schedule.once
+	}
+	if schedule.unit == unitMinute || schedule.unit == unitHour {
+		return schedule.nextInterval(from)
+	}
+	return schedule.nextDay(from, location)
+}
+
+func (t NaturalLanguageTrigger) IsRecurring() bool {
+	schedule, _ := t.getSchedule()
+	return schedule != nil && schedule.once == nil
+}
+
+// UnmarshalJSON() parses the normalized schedule, which isn't marshalled
+func (t *NaturalLanguageTrigger) UnmarshalJSON(bytes []byte) error {
+	type naturalLanguageTriggerJson NaturalLanguageTrigger
+	unmarshalled := naturalLanguageTriggerJson{}
+	err := json.Unmarshal(bytes, &unmarshalled)
+	if err != nil {
+		return err
+	}
+	*t = NaturalLanguageTrigger(unmarshalled)
+	t.location = t.getLocation()
+	schedule, err := parseNaturalLanguage(t.Normalized, time.Now(), t.location)
+	if err != nil {
+		return err
+	}
+	t.schedule = &schedule
+	return nil
+}
+
+func (t NaturalLanguageTrigger) getLocation() *time.Location {
+	if t.location != nil {
+		return t.location
+	}
+	if t.Location != "" {
+		location, err := time.LoadLocation(t.Location)
+		if err == nil {
+			return location
+		}
+	}
+	return time.UTC
+}
+
+// getSchedule() returns the parsed schedule, parsing the normalized form if the trigger was constructed without
+// NewNaturalLanguageTrigger()
+func (t NaturalLanguageTrigger) getSchedule() (*naturalLanguageSchedule, *time.Location) {
+	location := t.getLocation()
+	if t.schedule != nil {
+		return t.schedule, location
+	}
+	schedule, err := parseNaturalLanguage(t.Normalized, time.Now(), location)
+	if err != nil {
+		return nil, location
+	}
+	return &schedule, location
+}
+
+// NewNaturalLanguageTrigger() parses the text into a schedule evaluated in the given location, UTC if nil. Relative
+// phrases like "in 3 hours" or "tomorrow at noon" are resolved against now. Ambiguous text, like "at 9" or "03/04", is
+// rejected with an error explaining how to disambiguate it.
+func NewNaturalLanguageTrigger(text string, now time.Time, location *time.Location) (*NaturalLanguageTrigger, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	schedule, err := parseNaturalLanguage(text, now, location)
+	if err != nil {
+		return nil, err
+	}
+	trigger := &NaturalLanguageTrigger{
+		Text:       text,
+		Normalized: schedule.normalize(location),
+		Location:   location.String(),
+		schedule:   &schedule,
+		location:   location,
+	}
+	if schedule.once == nil && trigger.GetFireTime(now) == nil {
+		return nil, errorx.IllegalArgument.New("%q never fires", text)
+	}
+	return trigger, nil
+}
+
+// nextInterval() returns the first multiple of the period after from, counted from the start
+func (s naturalLanguageSchedule) nextInterval(from time.Time) *time.Time {
+	period := time.Duration(s.every) * time.Minute
+	if s.unit == unitHour {
+		period = time.Duration(s.every) * time.Hour
+	}
+	if from.Before(s.start) {
+		return &s.start
+	}
+	periods := from.Sub(s.start)/period + 1
+	fireTime := s.start.Add(periods * period)
+	return &fireTime
+}
+
+// nextDay() returns the first fire time after from for schedules with units of a day or longer, or nil if there isn't
+// one within the search limit
+func (s naturalLanguageSchedule) nextDay(from time.Time, location *time.Location) *time.Time {
+	timeOfDay := time.Duration(s.hour)*time.Hour + time.Duration(s.minute)*time.Minute
+	start := dateOf(s.start)
+	day := dateOf(from.In(location))
+	if day.Before(start) {
+		day = start
+	}
+	// every pattern repeats within a few years, 5th weekdays of the month are the rarest
+	searchDays := 366 * 7 * s.every
+	for i := 0; i < searchDays; i++ {
+		if s.firesOn(day, start) {
+			fireTime := fromWallClock(day.Add(timeOfDay), location)
+			if fireTime.After(from) {
+				return &fireTime
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return nil
+}
+
+// firesOn() returns true if the schedule fires on the day, both dates are midnight UTC
+func (s naturalLanguageSchedule) firesOn(day, start time.Time) bool {
+	switch s.unit {
+	case unitDay:
+		return daysBetween(start, day)%s.every == 0
+	case unitWeekday:
+		if !isBusinessDay(day) {
+			return false
+		}
+		first := start
+		for !isBusinessDay(first) {
+			first = first.AddDate(0, 0, 1)
+		}
+		return (businessDayIndex(day)-businessDayIndex(first))%s.every == 0
+	case unitWeek:
+		if !containsWeekday(s.weekdays, day.Weekday()) {
+			return false
+		}
+		return (daysBetween(startOfWeek(start), startOfWeek(day))/7)%s.every == 0
+	case unitMonth:
+		months := (day.Year()-start.Year())*12 + int(day.Month()) - int(start.Month())
+		if months%s.every != 0 {
+			return false
+		}
+		lastDay := day.AddDate(0, 1, -day.Day()).Day()
+		switch {
+		case s.monthDay == -1:
+			return day.Day() == lastDay
+		case s.monthDay > 0:
+			return day.Day() == s.monthDay
+		case day.Weekday() != s.weekday:
+			return false
+		case s.ordinal == -1:
+			return day.Day()+7 > lastDay
+		default:
+			return (day.Day()-1)/7+1 == s.ordinal
+		}
+	}
+	return false
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// startOfWeek() returns the monday on or before the day
+func startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+func isBusinessDay(day time.Time) bool {
+	return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+}
+
+// businessDayIndex() counts the business days between a fixed monday and the day
+func businessDayIndex(day time.Time) int {
+	epochMonday := time.Date(1970, time.January, 5, 0, 0, 0, 0, time.UTC)
+	days := daysBetween(epochMonday, day)
+	return days/7*5 + days%7
+}
+
+func containsWeekday(weekdays []time.Weekday, weekday time.Weekday) bool {
+	for _, candidate := range weekdays {
+		if candidate == weekday {
+			return true
+		}
+	}
+	return false
+}
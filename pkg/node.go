@@ -0,0 +1,140 @@
+package pkg
+
+import (
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/catalystsquad/app-utils-go/logging"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	modulePath               = "github.com/catalystsquad/go-scheduler"
+	defaultHeartbeatInterval = 5 * time.Second
+	// nodes that miss this many heartbeats in a row are considered dead
+	missedHeartbeatsUntilDead = 3
+	// dead nodes are kept around for a while so they show up in ListNodes() before the leader cleans them up
+	deadNodeRetention = 1 * time.Hour
+)
+
+type Node struct {
+	Id          *uuid.UUID `json:"id"`
+	Hostname    string     `json:"hostname"`
+	Version     string     `json:"version"`
+	StartedAt   *time.Time `json:"started_at"`
+	HeartbeatAt *time.Time `json:"heartbeat_at"`
+	Alive       bool       `json:"-"`
+}
+
+// WithHeartbeatInterval() sets how often the node updates its heartbeat in the store, defaults to 5 seconds. A node is
+// considered dead after missing 3 heartbeats
+func WithHeartbeatInterval(heartbeatInterval time.Duration) SchedulerOpt {
+	return func(s *Scheduler) {
+		s.heartbeatInterval = heartbeatInterval
+	}
+}
+
+// WithNodeVersion() sets the version the node registers with, defaults to the version of this module in the build info
+func WithNodeVersion(version string) SchedulerOpt {
+	return func(s *Scheduler) {
+		s.version = version
+	}
+}
+
+// NodeId() returns the id this scheduler registers with in the store
+func (s *Scheduler) NodeId() uuid.UUID {
+	return s.nodeId
+}
+
+// ListNodes() returns every node registered in the store, with Alive set based on the node's last heartbeat
+func (s *Scheduler) ListNodes() ([]Node, error) {
+	nodes, err := s.store.ListNodes()
+	if err != nil {
+		return nil, err
+	}
+	aliveAfter := s.nodeDeadline()
+	for i := range nodes {
+		nodes[i].Alive = nodes[i].HeartbeatAt != nil && nodes[i].HeartbeatAt.After(aliveAfter)
+	}
+	return nodes, nil
+}
+
+// ListTaskInstancesHeldByDeadNodes() returns in progress task instances that were claimed by a node that is no longer alive
+func (s *Scheduler) ListTaskInstancesHeldByDeadNodes() ([]TaskInstance, error) {
+	return s.store.ListTaskInstancesHeldByDeadNodes(s.nodeDeadline())
+}
+
+func (s *Scheduler) nodeDeadline() time.Time {
+	return time.Now().Add(-missedHeartbeatsUntilDead * s.heartbeatInterval)
+}
+
+func (s *Scheduler) registerNode() {
+	hostname, err := os.Hostname()
+	if err != nil {
+		logging.Log.WithError(err).Error("error getting hostname")
+	}
+	startedAt := time.Now().UTC()
+	s.node = Node{
+		Id:          &s.nodeId,
+		Hostname:    hostname,
+		Version:     s.version,
+		StartedAt:   &startedAt,
+		HeartbeatAt: &startedAt,
+	}
+	err = s.store.UpsertNode(s.node)
+	if err != nil {
+		logging.Log.WithError(err).WithFields(logrus.Fields{"node_id": s.nodeId}).Error("error registering node")
+	}
+	if s.sharding {
+		s.rebalanceShards()
+	}
+}
+
+func (s *Scheduler) startHeartbeat() {
+	ticker := time.NewTicker(s.heartbeatInterval)
+	for range ticker.C {
+		if s.run {
+			s.heartbeat()
+		} else {
+			ticker.Stop()
+			break
+		}
+	}
+}
+
+func (s *Scheduler) heartbeat() {
+	heartbeatAt := time.Now().UTC()
+	s.node.HeartbeatAt = &heartbeatAt
+	err := s.store.UpsertNode(s.node)
+	if err != nil {
+		logging.Log.WithError(err).WithFields(logrus.Fields{"node_id": s.nodeId}).Error("error updating node heartbeat")
+	}
+	if s.sharding {
+		s.rebalanceShards()
+	}
+}
+
+func (s *Scheduler) deregisterNode() {
+	err := s.store.DeleteNode(&s.nodeId)
+	if err != nil {
+		logging.Log.WithError(err).WithFields(logrus.Fields{"node_id": s.nodeId}).Error("error deregistering node")
+	}
+}
+
+func moduleVersion() string {
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if buildInfo.Main.Path == modulePath {
+		return buildInfo.Main.Version
+	}
+	for _, dep := range buildInfo.Deps {
+		if dep.Path == modulePath {
+			return dep.Version
+		}
+	}
+	return ""
+}
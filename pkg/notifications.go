@@ -0,0 +1,85 @@
+package pkg
+
+import (
+	"time"
+
+	"github.com/catalystsquad/app-utils-go/logging"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// WithNotifier() sets the notifier used to tell other nodes about upserted task definitions that need to be scheduled
+// right away. Without a notifier only the local node reacts, which is enough when every node schedules every task
+// definition, but with leader election or sharding another node may own the definition.
+func WithNotifier(notifier NotifierInterface) SchedulerOpt {
+	return func(s *Scheduler) {
+		s.notifier = notifier
+	}
+}
+
+// schedulesDefinition() returns true if this node is responsible for scheduling the task definition
+func (s *Scheduler) schedulesDefinition(definition TaskDefinition) bool {
+	if s.sharding {
+		return s.ownsShard(definition.Shard)
+	}
+	return s.IsLeader()
+}
+
+func (s *Scheduler) inScheduleWindow(definition TaskDefinition) bool {
+	return definition.NextFireTime != nil && !definition.NextFireTime.After(time.Now().Add(*s.ScheduleWindow))
+}
+
+// pickUp() schedules a task definition that fires within the current window on this node if this node is
+// responsible for it, otherwise it notifies the other nodes
+func (s *Scheduler) pickUp(definition TaskDefinition) {
+	if s.schedulesDefinition(definition) {
+		s.scheduleNow(definition.Id)
+		return
+	}
+	if s.notifier != nil {
+		err := s.notifier.Notify(definition)
+		if err != nil {
+			logging.Log.WithError(err).WithFields(logrus.Fields{"id": definition.Id}).Error("error notifying nodes of task definition")
+		}
+	}
+}
+
+// scheduleNow() creates a task instance for the task definition and dispatches it without waiting for the scheduler
+// and runner ticks
+func (s *Scheduler) scheduleNow(id *uuid.UUID) {
+	s.scheduleLock.Lock()
+	defer s.scheduleLock.Unlock()
+	// fetch the definition while holding the lock, the scheduler routine may have scheduled it in the meantime
+	definition, err := s.store.GetTaskDefinition(id)
+	if err != nil {
+		logging.Log.WithError(err).WithFields(logrus.Fields{"id": id}).Error("error getting task definition to schedule")
+		return
+	}
+	if definition.CompletedAt != nil || definition.Paused || !s.inScheduleWindow(definition) {
+		return
+	}
+	// errors are logged by scheduleTaskDefinition(), the task instances created before one are still dispatched
+	taskInstances, _ := s.scheduleTaskDefinition(definition)
+	for _, taskInstance := range taskInstances {
+		s.dispatch(taskInstance)
+	}
+}
+
+func (s *Scheduler) listenForNotifications() {
+	err := s.notifier.Listen(func(definitionId *uuid.UUID) {
+		if !s.run {
+			return
+		}
+		definition, err := s.store.GetTaskDefinition(definitionId)
+		if err != nil {
+			logging.Log.WithError(err).WithFields(logrus.Fields{"id": definitionId}).Error("error getting notified task definition")
+			return
+		}
+		if s.schedulesDefinition(definition) && s.inScheduleWindow(definition) {
+			s.scheduleNow(definitionId)
+		}
+	})
+	if err != nil {
+		logging.Log.WithError(err).Error("error listening for task definition notifications")
+	}
+}
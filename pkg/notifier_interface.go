@@ -0,0 +1,12 @@
+package pkg
+
+import "github.com/google/uuid"
+
+// NotifierInterface tells other nodes about task definitions that fire within the current window, so the node that
+// schedules them can do so right away instead of waiting for its next tick
+type NotifierInterface interface {
+	Notify(definition TaskDefinition) error
+	// Listen() calls the callback with the id of each notified task definition, blocking until Close() is called
+	Listen(callback func(definitionId *uuid.UUID)) error
+	Close() error
+}
@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+)
+
+// ResumeBehavior controls what happens to the fire times a task definition missed while it was paused
+type ResumeBehavior string
+
+const (
+	// ResumeSkipMissed drops the fire times missed while paused, recurring task definitions resume at their next fire
+	// time after now. Task definitions that fire once still fire. This is the default.
+	ResumeSkipMissed ResumeBehavior = "skip_missed"
+	// ResumeApplyMisfirePolicy treats the fire times missed while paused like any other misfire, the task definition's
+	// MisfirePolicy decides what happens to them
+	ResumeApplyMisfirePolicy ResumeBehavior = "apply_misfire_policy"
+)
+
+func (b ResumeBehavior) IsValid() bool {
+	switch b {
+	case "", ResumeSkipMissed, ResumeApplyMisfirePolicy:
+		return true
+	}
+	return false
+}
+
+// PauseTaskDefinition() puts the task definition on hold, no task instances are created or run for it until it's
+// resumed. Task instances already picked up by a node, up to a schedule window ahead, still run, and so do manually
+// triggered ones.
+func (s *Scheduler) PauseTaskDefinition(id *uuid.UUID) error {
+	if id == nil {
+		return errorx.IllegalArgument.New("an id must be provided")
+	}
+	return s.store.PauseTaskDefinition(id)
+}
+
+// ResumeTaskDefinition() takes the task definition off hold, the behavior decides what happens to the fire times it
+// missed while paused
+func (s *Scheduler) ResumeTaskDefinition(id *uuid.UUID, behavior ResumeBehavior) error {
+	if id == nil {
+		return errorx.IllegalArgument.New("an id must be provided")
+	}
+	if !behavior.IsValid() {
+		return errorx.IllegalArgument.New("invalid resume behavior: %s", behavior)
+	}
+	definition, err := s.store.GetTaskDefinition(id)
+	if err != nil {
+		return err
+	}
+	if !definition.Paused {
+		return nil
+	}
+	var nextFireTime *time.Time
+	missed := definition.NextFireTime != nil && definition.NextFireTime.Before(time.Now())
+	if behavior != ResumeApplyMisfirePolicy && definition.Recurring && missed {
+		nextFireTime = s.withCalendar(definition).GetNextFireTime()
+	}
+	err = s.store.ResumeTaskDefinition(id, nextFireTime)
+	if err != nil {
+		return err
+	}
+	if nextFireTime != nil {
+		definition.NextFireTime = nextFireTime
+	}
+	definition.Paused = false
+	definition.PausedAt = nil
+	// missed fire times and fire times within the current window would otherwise wait for the next scheduler tick
+	if s.run && s.inScheduleWindow(definition) {
+		s.pickUp(definition)
+	}
+	return nil
+}
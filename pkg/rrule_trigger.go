@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/joomcode/errorx"
+	"github.com/teambition/rrule-go"
+)
+
+// RRuleTrigger fires on an RFC 5545 recurrence, the DTSTART, RRULE, RDATE and EXDATE lines of an iCalendar event,
+// for example:
+//
+//	DTSTART;TZID=America/Chicago:20261020T093400
+//	RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;COUNT=10
+//	EXDATE;TZID=America/Chicago:20261117T093400
+type RRuleTrigger struct {
+	Recurrence string `json:"recurrence"`
+	// Location is the IANA time zone name for times without a TZID, UTC if empty
+	Location string `json:"location"`
+	set      *rrule.Set
+}
+
+// GetFireTime() returns the first occurrence after from, or nil once the recurrence is exhausted by COUNT or UNTIL
+func (t RRuleTrigger) GetFireTime(from time.Time) *time.Time {
+	set := t.set
+	if set == nil {
+		// constructed without NewRRuleTrigger()
+		var err error
+		set, err = parseRecurrence(t.Recurrence, t.getLocation())
+		if err != nil {
+			return nil
+		}
+	}
+	fireTime := set.After(from, false)
+	if fireTime.IsZero() {
+		return nil
+	}
+	return &fireTime
+}
+
+func (t RRuleTrigger) IsRecurring() bool {
+	return true
+}
+
+// UnmarshalJSON() rebuilds the parsed recurrence, which isn't marshalled
+func (t *RRuleTrigger) UnmarshalJSON(bytes []byte) error {
+	type rruleTriggerJson RRuleTrigger
+	unmarshalled := rruleTriggerJson{}
+	err := json.Unmarshal(bytes, &unmarshalled)
+	if err != nil {
+		return err
+	}
+	*t = RRuleTrigger(unmarshalled)
+	t.set, err = parseRecurrence(t.Recurrence, t.getLocation())
+	return err
+}
+
+// In() returns a copy of the trigger with times without a TZID in the given location
+func (t RRuleTrigger) In(location *time.Location) (*RRuleTrigger, error) {
+	return NewRRuleTriggerWithLocation(t.Recurrence, location)
+}
+
+func (t RRuleTrigger) getLocation() *time.Location {
+	if t.Location != "" {
+		location, err := time.LoadLocation(t.Location)
+		if err == nil {
+			return location
+		}
+	}
+	return time.UTC
+}
+
+func NewRRuleTrigger(recurrence string) (*RRuleTrigger, error) {
+	return NewRRuleTriggerWithLocation(recurrence, nil)
+}
+
+// NewRRuleTriggerWithLocation() returns a trigger for the recurrence with times without a TZID in the given location, a
+// nil location uses the scheduler's default location, which is UTC unless set with WithLocation()
+func NewRRuleTriggerWithLocation(recurrence string, location *time.Location) (*RRuleTrigger, error) {
+	trigger := &RRuleTrigger{Recurrence: strings.TrimSpace(recurrence)}
+	if location != nil {
+		trigger.Location = location.String()
+	}
+	set, err := parseRecurrence(trigger.Recurrence, trigger.getLocation())
+	if err != nil {
+		return nil, err
+	}
+	trigger.set = set
+	return trigger, nil
+}
+
+func parseRecurrence(recurrence string, location *time.Location) (*rrule.Set, error) {
+	lines := []string{}
+	for _, line := range strings.Split(recurrence, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, errorx.IllegalArgument.New("recurrence is empty")
+	}
+	if !strings.HasPrefix(strings.ToUpper(lines[0]), "DTSTART") {
+		return nil, errorx.IllegalArgument.New("recurrence must start with DTSTART")
+	}
+	set, err := rrule.StrSliceToRRuleSetInLoc(lines, location)
+	if err != nil {
+		return nil, errorx.IllegalArgument.Wrap(err, "invalid recurrence")
+	}
+	if set.GetRRule() == nil && len(set.GetRDate()) == 0 {
+		return nil, errorx.IllegalArgument.New("recurrence must have an RRULE or RDATE")
+	}
+	return set, nil
+}
@@ -12,6 +12,10 @@ import (
 	"time"
 )
 
+// maxUpsertAttempts caps how many times an update without a version is retried when the scheduler changes the task
+// definition at the same time
+const maxUpsertAttempts = 3
+
 type Scheduler struct {
 	ScheduleWindow *time.Duration
 	RunnerWindow   *time.Duration
@@ -19,11 +23,50 @@ type Scheduler struct {
 	Handler        func(taskInstance TaskInstance) error
 	store          StoreInterface
 	lock           *sync.Mutex
+	scheduleLock   *sync.Mutex
 	run            bool
 	shutdown       chan bool
+	nodeId         uuid.UUID
+
+	// node registry
+	node              Node
+	version           string
+	heartbeatInterval time.Duration
+
+	// leader election, when enabled only the leader schedules task instances and cleans up
+	leaderElection     bool
+	leaseDuration      time.Duration
+	leaderLeaseExpires time.Time
+
+	// sharding, when enabled each node only schedules and runs the shards it owns
+	sharding bool
+	shards   []int
+
+	// ids of task instances this node is waiting to run, so that they aren't dispatched more than once
+	pending map[uuid.UUID]bool
+	// the default location of cron triggers that don't have one
+	location *time.Location
+	// the default cap on catch up task instances for task definitions with the fire all misfire policy
+	maxMisfires int
+	// notifier tells other nodes about task definitions that need to be scheduled right away
+	notifier NotifierInterface
+	// calendars referenced by task definitions, cached for a schedule window
+	calendars map[string]cachedCalendar
+	// handlers and concurrency caps of namespaces
+	namespaceHandlers    map[string]func(taskInstance TaskInstance) error
+	namespaceSlots       map[string]chan struct{}
+	namespaceConcurrency map[string]int
+	// handlers registered with RegisterTypedHandler(), keyed by payload type
+	typedHandlers map[string]func(taskInstance TaskInstance) error
+	// encrypts task definition payloads, nil when encryption isn't enabled
+	keyProvider KeyProviderInterface
+	// tenant quotas, and the rate limiters of tenants with an execution rate
+	tenantQuotas       map[string]TenantQuota
+	defaultTenantQuota TenantQuota
+	tenantLimiters     map[string]*tenantRateLimiter
 }
 
-func NewScheduler(scheduleWindow, runnerWindow, cleanupWindow time.Duration, handler func(taskInstance TaskInstance) error, store StoreInterface) (*Scheduler, error) {
+func NewScheduler(scheduleWindow, runnerWindow, cleanupWindow time.Duration, handler func(taskInstance TaskInstance) error, store StoreInterface, opts ...SchedulerOpt) (*Scheduler, error) {
 	scheduler := &Scheduler{
 		ScheduleWindow: &scheduleWindow,
 		RunnerWindow:   &runnerWindow,
@@ -31,13 +74,66 @@ func NewScheduler(scheduleWindow, runnerWindow, cleanupWindow time.Duration, han
 		Handler:        handler,
 		store:          store,
 		lock:           new(sync.Mutex),
+		scheduleLock:   new(sync.Mutex),
 		shutdown:       make(chan bool, 1),
+		nodeId:         uuid.New(),
+
+		version:              moduleVersion(),
+		heartbeatInterval:    defaultHeartbeatInterval,
+		pending:              map[uuid.UUID]bool{},
+		calendars:            map[string]cachedCalendar{},
+		namespaceHandlers:    map[string]func(taskInstance TaskInstance) error{},
+		namespaceSlots:       map[string]chan struct{}{},
+		namespaceConcurrency: map[string]int{},
+		typedHandlers:        map[string]func(taskInstance TaskInstance) error{},
+		tenantQuotas:         map[string]TenantQuota{},
+		tenantLimiters:       map[string]*tenantRateLimiter{},
+		maxMisfires:          defaultMaxMisfires,
+	}
+	for _, opt := range opts {
+		opt(scheduler)
+	}
+	err := scheduler.validateOptions()
+	if err != nil {
+		return nil, err
 	}
-	err := scheduler.initializeStore()
+	err = scheduler.initializeStore()
 	return scheduler, err
 }
 
+type SchedulerOpt func(*Scheduler)
+
+// WithLocation() sets the location cron triggers without a location are evaluated in, defaults to UTC
+func WithLocation(location *time.Location) SchedulerOpt {
+	return func(s *Scheduler) {
+		s.location = location
+	}
+}
+
+// WithLeaderElection() enables leader election through the store. Only the elected node schedules task instances and
+// cleans up, every node runs task instances. The leader renews its lease every third of the lease duration, if the
+// leader dies another node takes over once the lease lapses.
+func WithLeaderElection(leaseDuration time.Duration) SchedulerOpt {
+	return func(s *Scheduler) {
+		s.leaderElection = true
+		s.leaseDuration = leaseDuration
+	}
+}
+
+// UpsertTaskDefinition() creates or updates the task definition. Updates with a Version only succeed if it's still the
+// stored version. Updates without one are made against the stored task definition as it's read here, and are retried
+// if the scheduler changes it in the meantime. Either way the fields the scheduler owns are kept: occurrences always,
+// and the next fire time and completed at unless the update changes when the task definition fires next.
 func (s *Scheduler) UpsertTaskDefinition(task TaskDefinition) error {
+	for attempt := 1; ; attempt++ {
+		err := s.upsertTaskDefinition(task)
+		if task.Version != 0 || attempt == maxUpsertAttempts || !errorx.IsOfType(err, VersionConflict) {
+			return err
+		}
+	}
+}
+
+func (s *Scheduler) upsertTaskDefinition(task TaskDefinition) error {
 	err := validateTask(task)
 	if err != nil {
 		return err
@@ -45,13 +141,93 @@ func (s *Scheduler) UpsertTaskDefinition(task TaskDefinition) error {
 	if task.ExpireAfter == 0 {
 		task.ExpireAfter = *s.ScheduleWindow
 	}
-	task.NextFireTime = task.GetNextFireTime()
-	task.Recurring = task.GetTrigger().IsRecurring()
+	if task.CronTrigger != nil && task.CronTrigger.Location == "" && s.location != nil {
+		task.CronTrigger = task.CronTrigger.In(s.location)
+	}
+	if task.WindowTrigger != nil && task.WindowTrigger.Location == "" && s.location != nil {
+		task.WindowTrigger = task.WindowTrigger.In(s.location)
+	}
+	if task.RRuleTrigger != nil && task.RRuleTrigger.Location == "" && s.location != nil {
+		task.RRuleTrigger, err = task.RRuleTrigger.In(s.location)
+		if err != nil {
+			return err
+		}
+	}
+	if task.CalendarName != "" {
+		calendar, err := s.getCachedCalendar(task.CalendarName)
+		if err != nil {
+			return err
+		}
+		task = task.WithCalendar(calendar)
+	}
 	if task.Id == nil || task.Id == &uuid.Nil {
 		id := uuid.New()
 		task.Id = &id
 	}
-	return s.store.UpsertTaskDefinition(task)
+	stored, err := s.getStoredTaskDefinition(task.Id)
+	if err != nil {
+		return err
+	}
+	if stored != nil {
+		// occurrences are counted by the scheduler, updating a task definition doesn't reset them
+		task.Occurrences = stored.Occurrences
+		if task.Version == 0 {
+			task.Version = stored.Version
+		}
+	}
+	now := time.Now()
+	task.NextFireTime = task.GetFireTimeFrom(now)
+	// task definitions with dependencies fire once per workflow run
+	task.Recurring = len(task.DependsOn) > 0 || task.GetTrigger().IsRecurring()
+	if stored != nil && sameTime(task.NextFireTime, s.withCalendar(*stored).GetFireTimeFrom(now)) {
+		// the update doesn't change when the task definition fires next, keep the scheduler's progress so that fire
+		// times it already created task instances for don't fire again
+		task.NextFireTime = stored.NextFireTime
+		task.CompletedAt = stored.CompletedAt
+	} else if task.Recurring && len(task.DependsOn) == 0 {
+		// recurring task definitions whose bounds are already used up are complete, so that they're cleaned up
+		task.CompletedAt = nil
+		if task.NextFireTime == nil {
+			completedAt := time.Now()
+			task.CompletedAt = &completedAt
+		}
+	}
+	err = s.validateDependencies(task)
+	if err != nil {
+		return err
+	}
+	task.Shard = ShardForId(task.Id)
+	if s.keyProvider != nil {
+		task, err = EncryptPayload(s.keyProvider, task)
+		if err != nil {
+			return err
+		}
+	}
+	err = s.store.UpsertTaskDefinition(task)
+	if err != nil {
+		return err
+	}
+	// tasks that fire within the current window would otherwise wait for the next scheduler and runner ticks
+	if s.run && s.inScheduleWindow(task) {
+		s.pickUp(task)
+	}
+	return nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// getStoredTaskDefinition() returns the stored task definition with the id, or nil if there isn't one
+func (s *Scheduler) getStoredTaskDefinition(id *uuid.UUID) (*TaskDefinition, error) {
+	definitions, err := s.store.GetTaskDefinitions([]*uuid.UUID{id})
+	if err != nil || len(definitions) == 0 {
+		return nil, err
+	}
+	return &definitions[0], nil
 }
 
 func (s *Scheduler) GetTaskDefinitions(ids []*uuid.UUID) ([]TaskDefinition, error) {
@@ -79,6 +255,16 @@ func (s *Scheduler) DeleteTaskDefinitionsByMetadataQuery(metadataQuery interface
 
 func (s *Scheduler) Run() {
 	s.run = true
+	// register this node and keep its heartbeat up to date
+	s.registerNode()
+	go s.startHeartbeat()
+	// start leader election first so that the scheduler and cleanup routines know if they should run
+	if s.leaderElection {
+		go s.startLeaderElection()
+	}
+	if s.notifier != nil {
+		go s.listenForNotifications()
+	}
 	// start task instance scheduler, task instance runner, and task instance cleanup, in background
 	go s.startTaskInstanceScheduler()
 	go s.startTaskInstanceRunner()
@@ -93,7 +279,9 @@ func (s *Scheduler) startTaskInstanceScheduler() {
 	ticker := time.NewTicker(*s.ScheduleWindow)
 	for range ticker.C {
 		if s.run {
-			s.createTaskInstances()
+			if s.schedulesTaskInstances() {
+				s.createTaskInstances()
+			}
 		} else {
 			ticker.Stop()
 			break
@@ -102,44 +290,80 @@ func (s *Scheduler) startTaskInstanceScheduler() {
 }
 
 func (s *Scheduler) createTaskInstances() {
-	taskDefinitions, err := s.store.GetTaskDefinitionsToSchedule(time.Now().Add(*s.ScheduleWindow))
+	// task definitions can also be scheduled right away when they're upserted, hold the lock so that the same
+	// definition isn't scheduled twice by this node
+	s.scheduleLock.Lock()
+	defer s.scheduleLock.Unlock()
+	taskDefinitions, err := s.store.GetTaskDefinitionsToSchedule(time.Now().Add(*s.ScheduleWindow), s.ownedShards())
 	if err != nil {
 		logging.Log.WithError(err).Error("error getting task definitions to run in window")
 		return
 	}
 	for _, taskDefinition := range taskDefinitions {
-		err = s.createTaskInstance(taskDefinition)
+		_, err = s.scheduleTaskDefinition(taskDefinition)
 		if err != nil {
 			logging.Log.WithError(err).Error("error creating task instance")
 		}
 	}
 }
 
-func (s *Scheduler) createTaskInstance(taskDefinition TaskDefinition) error {
-	executeAt := taskDefinition.GetNextFireTime()
-	expiresAt := executeAt.Add(taskDefinition.ExpireAfter)
-	taskInstance := TaskInstance{
-		ExpiresAt:      &expiresAt,
-		ExecuteAt:      executeAt,
-		TaskDefinition: taskDefinition,
+// scheduleTaskDefinition() creates task instances for the task definition's due fire times, and updates the task
+// definition's next fire time in the same store call
+func (s *Scheduler) scheduleTaskDefinition(taskDefinition TaskDefinition) ([]TaskInstance, error) {
+	taskDefinition = s.withCalendar(taskDefinition)
+	fireTimes, nextFireTime := s.dueFireTimes(taskDefinition, time.Now())
+	if taskDefinition.MaxOccurrences > 0 {
+		remaining := taskDefinition.MaxOccurrences - taskDefinition.Occurrences
+		if remaining < 0 {
+			remaining = 0
+		}
+		if len(fireTimes) > remaining {
+			fireTimes = fireTimes[:remaining]
+		}
 	}
-	err := s.store.UpsertTaskInstance(taskInstance)
-	if err != nil {
-		logging.Log.WithError(err).Error("error creating task instance")
-		return err
+	// task instances carry their task definition, count them before they're created
+	taskDefinition.Occurrences += len(fireTimes)
+	taskInstances := []TaskInstance{}
+	for _, fireTime := range fireTimes {
+		taskInstances = append(taskInstances, s.newTaskInstance(taskDefinition, fireTime))
+	}
+	if taskDefinition.MaxOccurrences > 0 && taskDefinition.Occurrences >= taskDefinition.MaxOccurrences {
+		nextFireTime = nil
 	}
 	// update task definition's next fire time, nil for non recurring triggers which will prevent creating more task instances
-	if taskDefinition.Recurring {
-		nextExecution := *taskDefinition.GetFireTimeFrom(*executeAt)
-		taskDefinition.NextFireTime = &nextExecution
-	} else {
-		taskDefinition.NextFireTime = nil
+	taskDefinition.NextFireTime = nextFireTime
+	if taskDefinition.Recurring && nextFireTime == nil && !firesAfterCompletion(taskDefinition.GetTrigger()) {
+		// recurring task definitions whose bounds are exhausted are complete, and are cleaned up once their task
+		// instances complete
+		completedAt := time.Now()
+		taskDefinition.CompletedAt = &completedAt
+	}
+	// only the fields the scheduler owns are updated, if the task definition was changed since it was read no task
+	// instances are created and it's scheduled again from the changed version on the next tick
+	created, err := s.store.ScheduleTaskDefinition(taskDefinition, taskInstances)
+	if errorx.IsOfType(err, VersionConflict) {
+		logging.Log.WithFields(logrus.Fields{"id": taskDefinition.Id}).Info("task definition changed while it was being scheduled")
+		return nil, nil
 	}
-	err = s.store.UpsertTaskDefinition(taskDefinition)
 	if err != nil {
-		logging.Log.WithError(err).WithFields(logrus.Fields{"id": taskDefinition.Id}).Error("error setting task definition next execution time")
+		logging.Log.WithError(err).WithFields(logrus.Fields{"id": taskDefinition.Id}).Error("error creating task instances")
+	}
+	return created, err
+}
+
+// newTaskInstance() returns a task instance for the task definition's fire time, it isn't stored
+func (s *Scheduler) newTaskInstance(taskDefinition TaskDefinition, fireTime time.Time) TaskInstance {
+	id := uuid.New()
+	executeAt := s.placeInWindow(taskDefinition, fireTime)
+	expiresAt := executeAt.Add(taskDefinition.ExpireAfter)
+	return TaskInstance{
+		Id:             &id,
+		ExpiresAt:      &expiresAt,
+		ExecuteAt:      &executeAt,
+		FireTime:       &fireTime,
+		TenantId:       taskDefinition.TenantId,
+		TaskDefinition: taskDefinition,
 	}
-	return err
 }
 
 func (s *Scheduler) startTaskInstanceRunner() {
@@ -156,34 +380,96 @@ func (s *Scheduler) startTaskInstanceRunner() {
 
 func (s *Scheduler) scheduleTaskInstanceRuns() {
 	// query for task instances that should be run
-	taskInstances, err := s.store.GetTaskInstancesToRun(time.Now().Add(*s.ScheduleWindow))
+	taskInstances, err := s.store.GetTaskInstancesToRun(time.Now().Add(*s.ScheduleWindow), s.ownedShards())
 	if err != nil {
 		logging.Log.WithError(err).Error("error getting task instances to run")
 		return
 	}
-	// handle each instance in a goroutine, the goroutine will sleep until its scheduled fire time
 	for _, taskInstance := range taskInstances {
-		go s.handleTaskInstance(taskInstance)
+		s.dispatch(taskInstance)
+	}
+}
+
+// dispatch() handles the task instance in a goroutine, the goroutine will sleep until its scheduled fire time. Task
+// instances this node is already waiting to run are skipped, so that overlapping windows don't run them twice
+func (s *Scheduler) dispatch(taskInstance TaskInstance) {
+	s.lock.Lock()
+	if s.pending[*taskInstance.Id] {
+		s.lock.Unlock()
+		return
 	}
+	s.pending[*taskInstance.Id] = true
+	s.lock.Unlock()
+	go s.handleTaskInstance(taskInstance)
 }
 
 func (s *Scheduler) handleTaskInstance(taskInstance TaskInstance) {
 	// sleep until the execution time
 	time.Sleep(time.Until(*taskInstance.ExecuteAt))
-	// mark task in progress
+	release := s.acquireNamespaceSlot(taskInstance)
+	defer release()
+	s.waitForTenantRate(taskInstance)
+	// mark task in progress, once it's in progress the store won't return it to run again until it expires
+	taskInstance.Attempts++
 	err := s.markTaskInstanceInProgress(taskInstance)
+	s.lock.Lock()
+	delete(s.pending, *taskInstance.Id)
+	s.lock.Unlock()
+	if err != nil {
+		return
+	}
+	// the handler gets the payload decrypted, the task instance itself keeps it encrypted
+	handlerTaskInstance, err := s.decryptTaskInstance(taskInstance)
 	if err != nil {
+		s.handleTaskInstanceError(taskInstance, err)
 		return
 	}
 	// call handler
-	err = s.Handler(taskInstance)
-	if err == nil {
-		// no error, mark instance completed
-		err = s.store.MarkTaskInstanceComplete(taskInstance)
-		if err != nil {
-			logging.Log.WithError(err).WithFields(logrus.Fields{"task_instance_id": taskInstance.Id, "task_definition_id": taskInstance.TaskDefinition.Id}).Error("error setting task instance completed_at")
+	err = s.getHandler(handlerTaskInstance)(handlerTaskInstance)
+	if err != nil {
+		s.handleTaskInstanceError(taskInstance, err)
+		return
+	}
+	s.completeTaskInstance(taskInstance, nil)
+}
+
+// completeTaskInstance() marks the task instance complete and advances its task definition, a non nil rescheduleAt
+// sets the task definition's next fire time
+func (s *Scheduler) completeTaskInstance(taskInstance TaskInstance, rescheduleAt *time.Time) {
+	err := s.markTaskInstanceDone(taskInstance, false, rescheduleAt)
+	if err != nil {
+		logging.Log.WithError(err).WithFields(logrus.Fields{"task_instance_id": taskInstance.Id, "task_definition_id": taskInstance.TaskDefinition.Id}).Error("error setting task instance completed_at")
+		return
+	}
+	s.advanceWorkflow(taskInstance)
+}
+
+// failTaskInstance() marks the task instance failed, it won't be run again. Its task definition is still advanced, so
+// triggers that fire after completion keep firing.
+func (s *Scheduler) failTaskInstance(taskInstance TaskInstance) {
+	err := s.markTaskInstanceDone(taskInstance, true, nil)
+	if err != nil {
+		logging.Log.WithError(err).WithFields(logrus.Fields{"task_instance_id": taskInstance.Id, "task_definition_id": taskInstance.TaskDefinition.Id}).Error("error setting task instance failed_at")
+		return
+	}
+	s.advanceWorkflow(taskInstance)
+}
+
+// markTaskInstanceDone() marks the task instance complete or failed, and reschedules its task definition in the same
+// store call when it needs to be. Triggers that fire after completion are scheduled from now, manually triggered task
+// instances don't affect the task definition's schedule.
+func (s *Scheduler) markTaskInstanceDone(taskInstance TaskInstance, failed bool, rescheduleAt *time.Time) error {
+	nextFireTime := rescheduleAt
+	if nextFireTime == nil {
+		if taskInstance.Manual || !firesAfterCompletion(taskInstance.TaskDefinition.GetTrigger()) {
+			if failed {
+				return s.store.MarkTaskInstanceFailed(taskInstance)
+			}
+			return s.store.MarkTaskInstanceComplete(taskInstance)
 		}
+		nextFireTime = s.withCalendar(taskInstance.TaskDefinition).GetNextFireTime()
 	}
+	return s.store.MarkTaskInstanceDoneAndReschedule(taskInstance, failed, nextFireTime)
 }
 
 func (s *Scheduler) markTaskInstanceInProgress(taskInstance TaskInstance) error {
@@ -191,6 +477,7 @@ func (s *Scheduler) markTaskInstanceInProgress(taskInstance TaskInstance) error
 	expiresAt := startedAt.Add(taskInstance.TaskDefinition.ExpireAfter)
 	taskInstance.StartedAt = &startedAt
 	taskInstance.ExpiresAt = &expiresAt
+	taskInstance.NodeId = &s.nodeId
 	err := s.store.UpsertTaskInstance(taskInstance)
 	if err != nil {
 		logging.Log.WithError(err).WithFields(logrus.Fields{"task_instance_id": taskInstance.Id, "task_definition_id": taskInstance.TaskDefinition.Id}).Error("error setting task instance started_at")
@@ -202,7 +489,9 @@ func (s *Scheduler) startTaskInstanceCleanup() {
 	ticker := time.NewTicker(*s.CleanupWindow)
 	for range ticker.C {
 		if s.run {
-			s.cleanUp()
+			if s.IsLeader() {
+				s.cleanUp()
+			}
 		} else {
 			ticker.Stop()
 			break
@@ -223,6 +512,11 @@ func (s *Scheduler) cleanUp() {
 	if err != nil {
 		logging.Log.WithError(err).Error("error deleting completed task definitions")
 	}
+	// delete nodes that have been dead for a while
+	err = s.store.DeleteNodesNotSeenSince(time.Now().Add(-deadNodeRetention))
+	if err != nil {
+		logging.Log.WithError(err).Error("error deleting dead nodes")
+	}
 }
 
 func (s *Scheduler) waitForOsSignal() {
@@ -239,6 +533,16 @@ func (s *Scheduler) Stop() {
 
 func (s *Scheduler) shutDown() {
 	s.run = false
+	if s.leaderElection {
+		s.resign()
+	}
+	s.deregisterNode()
+	if s.notifier != nil {
+		err := s.notifier.Close()
+		if err != nil {
+			logging.Log.WithError(err).Error("error closing notifier")
+		}
+	}
 	logging.Log.Info("scheduler stopped")
 }
 
@@ -249,12 +553,80 @@ func validateTask(task TaskDefinition) error {
 	if task.Metadata == nil {
 		return errorx.IllegalArgument.New("tasks must have metadata")
 	}
-	if task.GetTrigger() == nil {
+	if len(task.DependsOn) > 0 {
+		if task.GetTrigger() != nil {
+			return errorx.IllegalArgument.New("tasks with dependencies can't have a trigger, they fire when their dependencies complete")
+		}
+		if task.StartAt != nil || task.EndAt != nil || task.MaxOccurrences > 0 {
+			return errorx.IllegalArgument.New("start, end and max occurrences don't apply to tasks with dependencies")
+		}
+	} else if task.GetTrigger() == nil {
 		return errorx.IllegalArgument.New("tasks must have a trigger")
 	}
+	if task.IntervalTrigger != nil {
+		err := task.IntervalTrigger.validate()
+		if err != nil {
+			return err
+		}
+	}
+	if task.WindowTrigger != nil {
+		err := task.WindowTrigger.validate()
+		if err != nil {
+			return err
+		}
+	}
+	if task.Trigger != nil {
+		if _, ok := getTriggerRegistration(task.Trigger.TriggerInterface); !ok {
+			return errorx.IllegalArgument.New("trigger type %T isn't registered", task.Trigger.TriggerInterface)
+		}
+	}
+	if !task.MisfirePolicy.IsValid() {
+		return errorx.IllegalArgument.New("invalid misfire policy: %s", task.MisfirePolicy)
+	}
+	if task.MaxMisfires < 0 {
+		return errorx.IllegalArgument.New("max misfires must not be negative")
+	}
+	if !task.CalendarPolicy.IsValid() {
+		return errorx.IllegalArgument.New("invalid calendar policy: %s", task.CalendarPolicy)
+	}
+	if task.Jitter < 0 {
+		return errorx.IllegalArgument.New("jitter must not be negative")
+	}
+	if task.Jitter > 0 && task.Jitter < time.Millisecond {
+		// offsets are truncated to the millisecond, so a smaller jitter would never shift anything
+		return errorx.IllegalArgument.New("jitter must be at least a millisecond")
+	}
+	if !task.FailurePolicy.IsValid() {
+		return errorx.IllegalArgument.New("invalid failure policy: %s", task.FailurePolicy)
+	}
+	if task.MaxAttempts < 0 {
+		return errorx.IllegalArgument.New("max attempts must not be negative")
+	}
+	if task.MaxOccurrences < 0 {
+		return errorx.IllegalArgument.New("max occurrences must not be negative")
+	}
+	if (task.StartAt != nil || task.EndAt != nil || task.MaxOccurrences > 0) && task.GetTrigger() != nil && !task.GetTrigger().IsRecurring() {
+		return errorx.IllegalArgument.New("start, end and max occurrences only apply to recurring triggers")
+	}
+	if task.StartAt != nil && task.EndAt != nil && task.EndAt.Before(*task.StartAt) {
+		return errorx.IllegalArgument.New("end must not be before start")
+	}
 	return nil
 }
 
+// validateOptions() rejects option values that would panic or block once the scheduler runs, options can't return
+// errors themselves
+func (s *Scheduler) validateOptions() error {
+	// the lease is renewed every third of its duration, which must be a positive ticker interval
+	if s.leaderElection && s.leaseDuration < 3 {
+		return errorx.IllegalArgument.New("lease duration must be at least 3ns")
+	}
+	if s.heartbeatInterval <= 0 {
+		return errorx.IllegalArgument.New("heartbeat interval must be positive")
+	}
+	return s.initializeNamespaceSlots()
+}
+
 func (s *Scheduler) initializeStore() error {
 	return s.store.Initialize()
 }
@@ -0,0 +1,114 @@
+package pkg
+
+import (
+	"hash/fnv"
+	"sort"
+
+	"github.com/catalystsquad/app-utils-go/logging"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ShardCount is the fixed number of shards task definitions are hashed into. Changing it would reassign the shard of
+// every existing task definition, so it's a constant rather than an option
+const ShardCount = 256
+
+// ShardForId() returns the shard a task definition belongs to, the fnv32a hash of the id string modulo ShardCount
+func ShardForId(id *uuid.UUID) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(id.String()))
+	return int(hash.Sum32() % ShardCount)
+}
+
+// WithSharding() splits ownership of task definitions across live nodes. Each node only schedules and runs task
+// instances for the shards it owns, and ownership rebalances on every heartbeat as nodes join or leave. Shards are
+// assigned with rendezvous hashing so that only the shards of a joining or leaving node move.
+func WithSharding() SchedulerOpt {
+	return func(s *Scheduler) {
+		s.sharding = true
+	}
+}
+
+// schedulesTaskInstances() returns true if this node should run the task instance scheduler. With sharding every node
+// schedules its own shards, otherwise only the leader schedules
+func (s *Scheduler) schedulesTaskInstances() bool {
+	return s.sharding || s.IsLeader()
+}
+
+// ownedShards() returns the shards this node schedules and runs, nil if sharding is disabled which means all shards
+func (s *Scheduler) ownedShards() []int {
+	if !s.sharding {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	shards := make([]int, len(s.shards))
+	copy(shards, s.shards)
+	return shards
+}
+
+// ownsShard() returns true if this node schedules and runs the shard
+func (s *Scheduler) ownsShard(shard int) bool {
+	if !s.sharding {
+		return true
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, owned := range s.shards {
+		if owned == shard {
+			return true
+		}
+	}
+	return false
+}
+
+// rebalanceShards() recomputes the shards this node owns from the live nodes in the store
+func (s *Scheduler) rebalanceShards() {
+	nodes, err := s.ListNodes()
+	if err != nil {
+		logging.Log.WithError(err).WithFields(logrus.Fields{"node_id": s.nodeId}).Error("error listing nodes to rebalance shards")
+		return
+	}
+	liveNodeIds := []uuid.UUID{}
+	for _, node := range nodes {
+		if node.Alive {
+			liveNodeIds = append(liveNodeIds, *node.Id)
+		}
+	}
+	shards := assignShards(s.nodeId, liveNodeIds)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(shards) != len(s.shards) {
+		logging.Log.WithFields(logrus.Fields{"node_id": s.nodeId, "live_nodes": len(liveNodeIds), "shards": len(shards)}).Info("shard ownership changed")
+	}
+	s.shards = shards
+}
+
+// assignShards() returns the shards owned by nodeId, each shard is owned by the live node with the highest hash of
+// node id and shard. A node that isn't live owns nothing
+func assignShards(nodeId uuid.UUID, liveNodeIds []uuid.UUID) []int {
+	shards := []int{}
+	for shard := 0; shard < ShardCount; shard++ {
+		var owner uuid.UUID
+		var highest uint64
+		for _, liveNodeId := range liveNodeIds {
+			weight := rendezvousWeight(liveNodeId, shard)
+			if owner == uuid.Nil || weight > highest {
+				owner = liveNodeId
+				highest = weight
+			}
+		}
+		if owner == nodeId {
+			shards = append(shards, shard)
+		}
+	}
+	sort.Ints(shards)
+	return shards
+}
+
+func rendezvousWeight(nodeId uuid.UUID, shard int) uint64 {
+	hash := fnv.New64a()
+	hash.Write(nodeId[:])
+	hash.Write([]byte{byte(shard >> 8), byte(shard)})
+	return hash.Sum64()
+}
@@ -7,7 +7,21 @@ import (
 
 type StoreInterface interface {
 	Initialize() error
+	// UpsertTaskDefinition() should increment the task definition's version, and return a VersionConflict error if the
+	// definition has a version that isn't the current one. Updating a task definition that belongs to another tenant
+	// should return a TenantMismatch error.
 	UpsertTaskDefinition(definition TaskDefinition) error
+	// ScheduleTaskDefinition() updates only the fields the scheduler owns, the next fire time, completed at and
+	// occurrences, if the definition's version is still the current one, and creates the task instances in the same
+	// transaction. Task instances for a fire time that already has one are skipped, the ones created are returned.
+	// Returns a VersionConflict error, without creating any task instances, if the version isn't the current one.
+	ScheduleTaskDefinition(definition TaskDefinition, taskInstances []TaskInstance) ([]TaskInstance, error)
+	// ListTaskDefinitionsToRewrap() returns task definitions whose payload's data key was wrapped with a key other than
+	// the key with the id
+	ListTaskDefinitionsToRewrap(keyId string, limit int) ([]TaskDefinition, error)
+	// UpdateTaskDefinitionPayloadEnvelope() replaces the task definition's payload envelope, if its data key is still
+	// wrapped with the key with oldKeyId
+	UpdateTaskDefinitionPayloadEnvelope(id *uuid.UUID, oldKeyId string, envelope PayloadEnvelope) error
 	ListTaskDefinitions(offset, limit int, metadataQuery interface{}) ([]TaskDefinition, error)
 	GetTaskDefinition(id *uuid.UUID) (TaskDefinition, error)
 	GetTaskDefinitions(ids []*uuid.UUID) ([]TaskDefinition, error)
@@ -17,11 +31,73 @@ type StoreInterface interface {
 	UpsertTaskInstance(taskInstance TaskInstance) error
 	GetTaskInstance(id *uuid.UUID) (TaskInstance, error)
 	ListTaskInstances(offset, limit int) ([]TaskInstance, error)
+	// ListTaskInstancesForTaskDefinition() returns the task definition's task instances with a fire time between from and to, inclusive
+	ListTaskInstancesForTaskDefinition(definitionId *uuid.UUID, from, to time.Time) ([]TaskInstance, error)
 	DeleteTaskInstance(id *uuid.UUID) error
-	GetTaskDefinitionsToSchedule(limit time.Time) ([]TaskDefinition, error)
-	GetTaskInstancesToRun(limit time.Time) ([]TaskInstance, error)
-	// markTaskInstanceComplete() should also mark the task definition complete, if the definition is non-recurring
+	// GetTaskDefinitionsToSchedule() and GetTaskInstancesToRun() only return task definitions and instances in the given
+	// shards, all shards if shards is nil. They skip paused task definitions and their task instances, except manually
+	// triggered ones.
+	GetTaskDefinitionsToSchedule(limit time.Time, shards []int) ([]TaskDefinition, error)
+	GetTaskInstancesToRun(limit time.Time, shards []int) ([]TaskInstance, error)
+	// markTaskInstanceComplete() should also mark the task definition complete, if the definition is non-recurring and
+	// the task instance isn't manually triggered
 	MarkTaskInstanceComplete(instance TaskInstance) error
+	// RescheduleTaskDefinition() sets the task definition's next fire time and clears its completed at, a nil next fire
+	// time marks the task definition complete instead
+	RescheduleTaskDefinition(id *uuid.UUID, nextFireTime *time.Time) error
+	// MarkTaskInstanceDoneAndReschedule() marks the task instance complete, or failed if failed is true, and then
+	// reschedules its task definition like RescheduleTaskDefinition(), in one transaction
+	MarkTaskInstanceDoneAndReschedule(instance TaskInstance, failed bool, nextFireTime *time.Time) error
+	// DeleteCompletedTaskInstances() should skip task instances of workflow runs that haven't completed
 	DeleteCompletedTaskInstances() error
+	// DeleteCompletedTaskDefinitions() should skip completed task definitions that still have incomplete task instances
+	// or workflow runs
 	DeleteCompletedTaskDefinitions() error
+	// AcquireLease() takes the named lease for the holder if it's free, expired, or already held by the holder, and
+	// extends it by ttl. Returns true if the holder owns the lease afterwards
+	AcquireLease(name string, holderId *uuid.UUID, ttl time.Duration) (bool, error)
+	// ReleaseLease() gives up the named lease if it's held by the holder
+	ReleaseLease(name string, holderId *uuid.UUID) error
+	UpsertNode(node Node) error
+	ListNodes() ([]Node, error)
+	DeleteNode(id *uuid.UUID) error
+	DeleteNodesNotSeenSince(limit time.Time) error
+	// ListTaskInstancesHeldByDeadNodes() returns in progress task instances whose node hasn't heartbeat since the limit,
+	// or no longer exists
+	ListTaskInstancesHeldByDeadNodes(limit time.Time) ([]TaskInstance, error)
+	UpsertCalendar(calendar Calendar) error
+	GetCalendar(name string) (Calendar, error)
+	ListCalendars() ([]Calendar, error)
+	DeleteCalendar(name string) error
+	// PauseTaskDefinition() marks the task definition paused, UpsertTaskDefinition() shouldn't change whether a task
+	// definition is paused
+	PauseTaskDefinition(id *uuid.UUID) error
+	// ResumeTaskDefinition() clears the task definition's paused state. A non nil next fire time replaces the task
+	// definition's next fire time, and drops its task instances that were due before now and haven't started.
+	ResumeTaskDefinition(id *uuid.UUID, nextFireTime *time.Time) error
+	// ListTaskDefinitionsByFilter(), CountTaskDefinitionsByFilter() and DeleteTaskDefinitionsByFilter() select task
+	// definitions with a structured filter, tenant views rely on them to constrain every query to the tenant
+	ListTaskDefinitionsByFilter(filter TaskDefinitionFilter, offset, limit int) ([]TaskDefinition, error)
+	CountTaskDefinitionsByFilter(filter TaskDefinitionFilter) (int64, error)
+	DeleteTaskDefinitionsByFilter(filter TaskDefinitionFilter) error
+	ListTaskDefinitionsInNamespace(namespace string, offset, limit int) ([]TaskDefinition, error)
+	CountTaskDefinitionsInNamespace(namespace string) (int64, error)
+	PauseTaskDefinitionsInNamespace(namespace string) error
+	DeleteTaskDefinitionsInNamespace(namespace string) error
+	// CountTaskInstancesInSlots() returns the number of incomplete task instances that execute in each of the
+	// consecutive slots starting at from
+	CountTaskInstancesInSlots(from time.Time, slot time.Duration, slots int) ([]int, error)
+	// InsertTaskInstance() creates the task instance if there isn't one with its id, returns true if it was created
+	InsertTaskInstance(taskInstance TaskInstance) (bool, error)
+	// MarkTaskInstanceFailed() marks the task instance failed and complete, and completes its task definition like
+	// MarkTaskInstanceComplete()
+	MarkTaskInstanceFailed(instance TaskInstance) error
+	// ListDependentTaskDefinitions() returns the task definitions that depend on the task definition
+	ListDependentTaskDefinitions(id *uuid.UUID) ([]TaskDefinition, error)
+	// StartWorkflowRun() creates the workflow run if it doesn't exist, and sets the root task instance's workflow run id
+	StartWorkflowRun(run WorkflowRun, rootTaskInstanceId *uuid.UUID) error
+	UpsertWorkflowRun(run WorkflowRun) error
+	GetWorkflowRun(id *uuid.UUID) (WorkflowRun, error)
+	ListWorkflowRuns(rootTaskDefinitionId *uuid.UUID, offset, limit int) ([]WorkflowRun, error)
+	ListTaskInstancesForWorkflowRun(id *uuid.UUID) ([]TaskInstance, error)
 }
@@ -7,15 +7,69 @@ import (
 )
 
 type TaskDefinition struct {
-	Id                 *uuid.UUID
-	Metadata           interface{}         `json:"metadata"`
-	ExpireAfter        time.Duration       `json:"expire_after"`
-	NextFireTime       *time.Time          `json:"next_fire_time"`
-	ExecuteOnceTrigger *ExecuteOnceTrigger `json:"execute_once_trigger"`
-	CronTrigger        *CronTrigger        `json:"cron_trigger"`
-	CompletedAt        *time.Time          `json:"completed_at"`
-	Recurring          bool                `json:"recurring"`
-	TaskInstances      []TaskInstance      `json:"task_instances" gorm:"foreignKey:Id"`
+	Id *uuid.UUID
+	// TenantId is the tenant the task definition belongs to, set by tenant views, see ForTenant()
+	TenantId string `json:"tenant_id"`
+	// Key is an optional business key, unique per tenant in the store, see UpsertTaskDefinitionByKey()
+	Key string `json:"key"`
+	// Version is incremented by the store on every write. Upserting a task definition with a version only succeeds if
+	// it's still the current version, otherwise a VersionConflict error is returned. Upserting with a version of 0 is
+	// made against the stored version and retried if the scheduler changes it in the meantime, a VersionConflict error
+	// is returned if it's still changing after a few attempts. Either way the fields the scheduler owns are kept, see
+	// UpsertTaskDefinition().
+	Version int64 `json:"version"`
+	// Namespace groups task definitions so they can be managed together, see ListNamespaceTaskDefinitions()
+	Namespace string      `json:"namespace"`
+	Metadata  interface{} `json:"metadata"`
+	// PayloadType and Payload are set from a TypedTask, see RegisterTypedHandler()
+	PayloadType string `json:"payload_type"`
+	Payload     []byte `json:"payload"`
+	// PayloadEnvelope is set when the payload is encrypted, see WithEncryption()
+	PayloadEnvelope        *PayloadEnvelope        `json:"payload_envelope"`
+	ExpireAfter            time.Duration           `json:"expire_after"`
+	NextFireTime           *time.Time              `json:"next_fire_time"`
+	ExecuteOnceTrigger     *ExecuteOnceTrigger     `json:"execute_once_trigger"`
+	CronTrigger            *CronTrigger            `json:"cron_trigger"`
+	IntervalTrigger        *IntervalTrigger        `json:"interval_trigger"`
+	NaturalLanguageTrigger *NaturalLanguageTrigger `json:"natural_language_trigger"`
+	RRuleTrigger           *RRuleTrigger           `json:"rrule_trigger"`
+	WindowTrigger          *WindowTrigger          `json:"window_trigger"`
+	// Trigger holds custom triggers registered with RegisterTrigger()
+	Trigger       *RegisteredTrigger `json:"trigger"`
+	CompletedAt   *time.Time         `json:"completed_at"`
+	Recurring     bool               `json:"recurring"`
+	Shard         int                `json:"shard"`
+	MisfirePolicy MisfirePolicy      `json:"misfire_policy"`
+	// MaxMisfires caps the number of catch up task instances created by MisfirePolicyFireAll, 0 uses the scheduler's default
+	MaxMisfires int `json:"max_misfires"`
+	// StartAt and EndAt bound the fire times of recurring task definitions
+	StartAt *time.Time `json:"start_at"`
+	EndAt   *time.Time `json:"end_at"`
+	// MaxOccurrences caps the number of task instances created for a recurring task definition, 0 for no cap.
+	// Occurrences counts the task instances created so far, and is maintained by the scheduler.
+	MaxOccurrences int `json:"max_occurrences"`
+	Occurrences    int `json:"occurrences"`
+	// CalendarName is the name of a calendar whose excluded periods the task definition doesn't fire in, and
+	// CalendarPolicy decides whether fire times in those periods are skipped or deferred
+	CalendarName   string         `json:"calendar_name"`
+	CalendarPolicy CalendarPolicy `json:"calendar_policy"`
+	// Jitter spreads out task definitions with the same schedule, fire times are shifted by a pseudo random offset
+	// less than Jitter, see JitterOffset(). Offsets are whole milliseconds, so Jitter must be at least a millisecond.
+	Jitter time.Duration `json:"jitter"`
+	// DependsOn makes the task definition part of a workflow, instead of a trigger it fires once per workflow run when
+	// the task instances of the task definitions it depends on complete. FailurePolicy decides what happens when one of
+	// them fails.
+	DependsOn     []*uuid.UUID  `json:"depends_on"`
+	FailurePolicy FailurePolicy `json:"failure_policy"`
+	// MaxAttempts is the number of times a task instance is run before it's marked as failed, 0 retries until it
+	// succeeds
+	MaxAttempts int `json:"max_attempts"`
+	// Paused is set by PauseTaskDefinition() and cleared by ResumeTaskDefinition(), upserting a task definition doesn't
+	// change it
+	Paused        bool           `json:"paused"`
+	PausedAt      *time.Time     `json:"paused_at"`
+	TaskInstances []TaskInstance `json:"task_instances" gorm:"foreignKey:Id"`
+	calendar      *Calendar
 }
 
 func (t TaskDefinition) GetIdBytes() []byte {
@@ -37,6 +91,16 @@ func (t TaskDefinition) GetTrigger() TriggerInterface {
 		return t.CronTrigger
 	} else if t.ExecuteOnceTrigger != nil {
 		return t.ExecuteOnceTrigger
+	} else if t.IntervalTrigger != nil {
+		return t.IntervalTrigger
+	} else if t.NaturalLanguageTrigger != nil {
+		return t.NaturalLanguageTrigger
+	} else if t.RRuleTrigger != nil {
+		return t.RRuleTrigger
+	} else if t.WindowTrigger != nil {
+		return t.WindowTrigger
+	} else if t.Trigger != nil && t.Trigger.TriggerInterface != nil {
+		return t.Trigger.TriggerInterface
 	}
 	return nil
 }
@@ -45,10 +109,29 @@ func (t TaskDefinition) IdString() string {
 	return t.Id.String()
 }
 
+// GetFireTimeFrom() returns the trigger's first fire time after from within the task definition's bounds, or nil once
+// the bounds are exhausted
 func (t TaskDefinition) GetFireTimeFrom(from time.Time) *time.Time {
-	return t.GetTrigger().GetFireTime(from)
+	if t.MaxOccurrences > 0 && t.Occurrences >= t.MaxOccurrences {
+		return nil
+	}
+	return t.getFireTimeWithinBounds(from)
 }
 
 func (t TaskDefinition) GetNextFireTime() *time.Time {
-	return t.GetTrigger().GetFireTime(time.Now())
+	return t.GetFireTimeFrom(time.Now())
+}
+
+// getFireTimeWithinBounds() returns the trigger's first fire time after from between StartAt and EndAt, outside of the
+// calendar's excluded periods and shifted by the jitter offset, ignoring MaxOccurrences
+func (t TaskDefinition) getFireTimeWithinBounds(from time.Time) *time.Time {
+	if t.StartAt != nil && from.Before(*t.StartAt) {
+		// triggers return the first fire time after the given time, step back so that StartAt itself can fire
+		from = t.StartAt.Add(-time.Nanosecond)
+	}
+	fireTime := t.applyCalendar(t.getJitteredFireTime(from))
+	if fireTime != nil && t.EndAt != nil && fireTime.After(*t.EndAt) {
+		return nil
+	}
+	return fireTime
 }
@@ -6,10 +6,37 @@ import (
 )
 
 type TaskInstance struct {
-	Id             *uuid.UUID     `json:"id"`
-	ExpiresAt      *time.Time     `json:"expires_at"`
-	ExecuteAt      *time.Time     `json:"execute_at"`
-	StartedAt      *time.Time     `json:"started_at"`
-	CompletedAt    *time.Time     `json:"completed_at"`
+	Id *uuid.UUID `json:"id"`
+	// TenantId is the tenant of the task instance's task definition
+	TenantId    string     `json:"tenant_id"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	ExecuteAt   *time.Time `json:"execute_at"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	NodeId      *uuid.UUID `json:"node_id"`
+	// FireTime is the trigger fire time the task instance is for, which differs from ExecuteAt for backfilled instances
+	FireTime *time.Time `json:"fire_time"`
+	Backfill bool       `json:"backfill"`
+	// Manual is true for task instances created by TriggerNow()
+	Manual bool `json:"manual"`
+	// Metadata overrides the task definition's metadata for this task instance, see GetMetadata()
+	Metadata interface{} `json:"metadata"`
+	// Attempts counts the times the task instance has been run
+	Attempts int `json:"attempts"`
+	// FailedAt is set once the task instance fails its task definition's MaxAttempts, failed task instances are also
+	// completed
+	FailedAt *time.Time `json:"failed_at"`
+	// WorkflowRunId is the workflow run the task instance is part of, and Skipped is true for task instances that were
+	// skipped because of a failed dependency
+	WorkflowRunId  *uuid.UUID     `json:"workflow_run_id"`
+	Skipped        bool           `json:"skipped"`
 	TaskDefinition TaskDefinition `json:"task_definition"`
 }
+
+// GetMetadata() returns the task instance's metadata override if it has one, otherwise its task definition's metadata
+func (t TaskInstance) GetMetadata() interface{} {
+	if t.Metadata != nil {
+		return t.Metadata
+	}
+	return t.TaskDefinition.Metadata
+}
@@ -0,0 +1,116 @@
+package pkg
+
+import (
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+)
+
+// KeyConflictMode controls what UpsertTaskDefinitionByKey() does when a task definition with the key already exists
+type KeyConflictMode string
+
+const (
+	// KeyConflictReplace replaces the existing task definition, keeping its id. This is the default.
+	KeyConflictReplace KeyConflictMode = "replace"
+	// KeyConflictKeep leaves the existing task definition alone and returns it
+	KeyConflictKeep KeyConflictMode = "keep"
+	// KeyConflictError returns a DuplicateKey error
+	KeyConflictError KeyConflictMode = "error"
+)
+
+func (m KeyConflictMode) IsValid() bool {
+	switch m {
+	case "", KeyConflictReplace, KeyConflictKeep, KeyConflictError:
+		return true
+	}
+	return false
+}
+
+// UpsertTaskDefinitionByKey() upserts the task definition by its Key instead of its id, so that retried calls don't
+// create duplicates. Returns the task definition that was stored, or the existing one when keeping it.
+func (s *Scheduler) UpsertTaskDefinitionByKey(task TaskDefinition, mode KeyConflictMode) (TaskDefinition, error) {
+	return s.upsertTaskDefinitionByKey(task, mode, s.UpsertTaskDefinition)
+}
+
+func (s *Scheduler) GetTaskDefinitionByKey(key string) (TaskDefinition, error) {
+	return s.getTaskDefinitionByKey("", key)
+}
+
+func (s *Scheduler) DeleteTaskDefinitionByKey(key string) error {
+	return s.deleteTaskDefinitionByKey("", key)
+}
+
+func (t *TenantScheduler) UpsertTaskDefinitionByKey(task TaskDefinition, mode KeyConflictMode) (TaskDefinition, error) {
+	if task.TenantId != "" && task.TenantId != t.tenantId {
+		return TaskDefinition{}, TenantMismatch.New("task definition belongs to tenant %s", task.TenantId)
+	}
+	task.TenantId = t.tenantId
+	return t.scheduler.upsertTaskDefinitionByKey(task, mode, t.UpsertTaskDefinition)
+}
+
+func (t *TenantScheduler) GetTaskDefinitionByKey(key string) (TaskDefinition, error) {
+	return t.scheduler.getTaskDefinitionByKey(t.tenantId, key)
+}
+
+func (t *TenantScheduler) DeleteTaskDefinitionByKey(key string) error {
+	return t.scheduler.deleteTaskDefinitionByKey(t.tenantId, key)
+}
+
+// upsertTaskDefinitionByKey() looks up the key in the task definition's tenant and upserts with the given function
+func (s *Scheduler) upsertTaskDefinitionByKey(task TaskDefinition, mode KeyConflictMode, upsert func(TaskDefinition) error) (TaskDefinition, error) {
+	if task.Key == "" {
+		return TaskDefinition{}, errorx.IllegalArgument.New("a key must be provided")
+	}
+	if !mode.IsValid() {
+		return TaskDefinition{}, errorx.IllegalArgument.New("invalid key conflict mode %s", mode)
+	}
+	// another upsert can create the key between the lookup and the upsert, in which case the store rejects the
+	// duplicate and the lookup is done once more
+	for attempt := 0; ; attempt++ {
+		existing, err := s.getTaskDefinitionByKey(task.TenantId, task.Key)
+		if err != nil && !errorx.IsNotFound(err) {
+			return TaskDefinition{}, err
+		}
+		if err == nil {
+			switch mode {
+			case KeyConflictKeep:
+				return existing, nil
+			case KeyConflictError:
+				return TaskDefinition{}, DuplicateKey.New("task definition with key %s already exists", task.Key)
+			}
+			task.Id = existing.Id
+		} else if task.Id == nil {
+			id := uuid.New()
+			task.Id = &id
+		}
+		err = upsert(task)
+		if errorx.IsOfType(err, DuplicateKey) && attempt == 0 && mode != KeyConflictError {
+			continue
+		}
+		if err != nil {
+			return TaskDefinition{}, err
+		}
+		// the upsert merges in the existing version and the fields the scheduler owns, return what was stored
+		return s.store.GetTaskDefinition(task.Id)
+	}
+}
+
+func (s *Scheduler) getTaskDefinitionByKey(tenantId, key string) (TaskDefinition, error) {
+	if key == "" {
+		return TaskDefinition{}, errorx.IllegalArgument.New("a key must be provided")
+	}
+	definitions, err := s.store.ListTaskDefinitionsByFilter(TaskDefinitionFilter{TenantId: tenantId, Key: key}, 0, 1)
+	if err != nil {
+		return TaskDefinition{}, err
+	}
+	if len(definitions) == 0 {
+		return TaskDefinition{}, TaskDefinitionNotFound.New("task definition with key %s not found", key)
+	}
+	return definitions[0], nil
+}
+
+func (s *Scheduler) deleteTaskDefinitionByKey(tenantId, key string) error {
+	if key == "" {
+		return errorx.IllegalArgument.New("a key must be provided")
+	}
+	return s.store.DeleteTaskDefinitionsByFilter(TaskDefinitionFilter{TenantId: tenantId, Key: key})
+}
@@ -0,0 +1,308 @@
+package pkg
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+)
+
+// TaskDefinitionFilter selects task definitions by their fields, empty fields match every task definition
+type TaskDefinitionFilter struct {
+	TenantId string       `json:"tenant_id"`
+	Ids      []*uuid.UUID `json:"ids"`
+	// Key matches the task definition with the key in TenantId's tenant, keys are unique per tenant so a key always
+	// matches on the tenant, even an empty one
+	Key       string `json:"key"`
+	Namespace string `json:"namespace"`
+	// Metadata matches task definitions whose metadata contains it
+	Metadata map[string]interface{} `json:"metadata"`
+	Paused   *bool                  `json:"paused"`
+}
+
+type TenantQuota struct {
+	// MaxTaskDefinitions caps the number of task definitions the tenant can have, 0 for no cap. The cap is best effort,
+	// the count and the insert aren't atomic, so concurrent upserts of new task definitions can go over it.
+	MaxTaskDefinitions int64
+	// MaxExecutionsPerSecond caps how many of the tenant's task instances each node starts per second, task instances
+	// over the rate wait their turn. 0 for no cap.
+	MaxExecutionsPerSecond float64
+}
+
+// WithTenantQuota() sets the quota of a tenant, overriding the default quota
+func WithTenantQuota(tenantId string, quota TenantQuota) SchedulerOpt {
+	return func(s *Scheduler) {
+		s.tenantQuotas[tenantId] = quota
+	}
+}
+
+// WithDefaultTenantQuota() sets the quota of tenants that don't have their own quota, defaults to no quota
+func WithDefaultTenantQuota(quota TenantQuota) SchedulerOpt {
+	return func(s *Scheduler) {
+		s.defaultTenantQuota = quota
+	}
+}
+
+func (s *Scheduler) getTenantQuota(tenantId string) TenantQuota {
+	if quota, ok := s.tenantQuotas[tenantId]; ok {
+		return quota
+	}
+	return s.defaultTenantQuota
+}
+
+// tenantRateLimiter spaces out the starts of a tenant's task instances on this node
+type tenantRateLimiter struct {
+	lock     sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *tenantRateLimiter) wait() {
+	l.lock.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.lock.Unlock()
+	time.Sleep(wait)
+}
+
+// waitForTenantRate() blocks until the task instance's tenant is under its execution rate
+func (s *Scheduler) waitForTenantRate(taskInstance TaskInstance) {
+	tenantId := taskInstance.TaskDefinition.TenantId
+	if tenantId == "" {
+		return
+	}
+	quota := s.getTenantQuota(tenantId)
+	if quota.MaxExecutionsPerSecond <= 0 {
+		return
+	}
+	s.lock.Lock()
+	limiter, ok := s.tenantLimiters[tenantId]
+	if !ok {
+		limiter = &tenantRateLimiter{interval: time.Duration(float64(time.Second) / quota.MaxExecutionsPerSecond)}
+		s.tenantLimiters[tenantId] = limiter
+	}
+	s.lock.Unlock()
+	limiter.wait()
+}
+
+// TenantScheduler is a view of the scheduler that only sees one tenant's task definitions, every store query it makes
+// is constrained to the tenant. Task definitions it upserts belong to the tenant, and count against the tenant's quota.
+type TenantScheduler struct {
+	scheduler *Scheduler
+	tenantId  string
+}
+
+// ForTenant() returns a view of the scheduler scoped to the tenant
+func (s *Scheduler) ForTenant(tenantId string) (*TenantScheduler, error) {
+	if tenantId == "" {
+		return nil, errorx.IllegalArgument.New("a tenant id must be provided")
+	}
+	return &TenantScheduler{scheduler: s, tenantId: tenantId}, nil
+}
+
+func (t *TenantScheduler) TenantId() string {
+	return t.tenantId
+}
+
+// scoped() returns the filter constrained to the tenant
+func (t *TenantScheduler) scoped(filter TaskDefinitionFilter) TaskDefinitionFilter {
+	filter.TenantId = t.tenantId
+	return filter
+}
+
+func (t *TenantScheduler) UpsertTaskDefinition(task TaskDefinition) error {
+	if task.TenantId != "" && task.TenantId != t.tenantId {
+		return TenantMismatch.New("task definition belongs to tenant %s", task.TenantId)
+	}
+	task.TenantId = t.tenantId
+	if task.Id == nil {
+		id := uuid.New()
+		task.Id = &id
+	}
+	existing, err := t.scheduler.store.ListTaskDefinitionsByFilter(TaskDefinitionFilter{Ids: []*uuid.UUID{task.Id}}, 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 && existing[0].TenantId != t.tenantId {
+		return TenantMismatch.New("task definition %s belongs to another tenant", task.Id)
+	}
+	// workflows can't depend on another tenant's task definitions
+	for _, dependencyId := range task.DependsOn {
+		_, err = t.GetTaskDefinition(dependencyId)
+		if err != nil {
+			return err
+		}
+	}
+	quota := t.scheduler.getTenantQuota(t.tenantId)
+	if len(existing) == 0 && quota.MaxTaskDefinitions > 0 {
+		count, err := t.scheduler.store.CountTaskDefinitionsByFilter(t.scoped(TaskDefinitionFilter{}))
+		if err != nil {
+			return err
+		}
+		if count >= quota.MaxTaskDefinitions {
+			return QuotaExceeded.New("tenant %s has reached its quota of %d task definitions", t.tenantId, quota.MaxTaskDefinitions)
+		}
+	}
+	return t.scheduler.UpsertTaskDefinition(task)
+}
+
+func (t *TenantScheduler) GetTaskDefinition(id *uuid.UUID) (TaskDefinition, error) {
+	if id == nil {
+		return TaskDefinition{}, errorx.IllegalArgument.New("an id must be provided")
+	}
+	definitions, err := t.scheduler.store.ListTaskDefinitionsByFilter(t.scoped(TaskDefinitionFilter{Ids: []*uuid.UUID{id}}), 0, 1)
+	if err != nil {
+		return TaskDefinition{}, err
+	}
+	if len(definitions) == 0 {
+		return TaskDefinition{}, TaskDefinitionNotFound.New("task definition %s not found", id)
+	}
+	return definitions[0], nil
+}
+
+func (t *TenantScheduler) ListTaskDefinitions(offset, limit int, filter TaskDefinitionFilter) ([]TaskDefinition, error) {
+	return t.scheduler.store.ListTaskDefinitionsByFilter(t.scoped(filter), offset, limit)
+}
+
+func (t *TenantScheduler) CountTaskDefinitions(filter TaskDefinitionFilter) (int64, error) {
+	return t.scheduler.store.CountTaskDefinitionsByFilter(t.scoped(filter))
+}
+
+func (t *TenantScheduler) DeleteTaskDefinition(id *uuid.UUID) error {
+	if id == nil {
+		return errorx.IllegalArgument.New("an id must be provided")
+	}
+	return t.scheduler.store.DeleteTaskDefinitionsByFilter(t.scoped(TaskDefinitionFilter{Ids: []*uuid.UUID{id}}))
+}
+
+// DeleteTaskDefinitions() deletes the tenant's task definitions that match the filter
+func (t *TenantScheduler) DeleteTaskDefinitions(filter TaskDefinitionFilter) error {
+	return t.scheduler.store.DeleteTaskDefinitionsByFilter(t.scoped(filter))
+}
+
+func (t *TenantScheduler) PauseTaskDefinition(id *uuid.UUID) error {
+	_, err := t.GetTaskDefinition(id)
+	if err != nil {
+		return err
+	}
+	return t.scheduler.PauseTaskDefinition(id)
+}
+
+func (t *TenantScheduler) ResumeTaskDefinition(id *uuid.UUID, behavior ResumeBehavior) error {
+	_, err := t.GetTaskDefinition(id)
+	if err != nil {
+		return err
+	}
+	return t.scheduler.ResumeTaskDefinition(id, behavior)
+}
+
+func (t *TenantScheduler) TriggerNow(id *uuid.UUID, opts TriggerNowOptions) (TaskInstance, error) {
+	_, err := t.GetTaskDefinition(id)
+	if err != nil {
+		return TaskInstance{}, err
+	}
+	return t.scheduler.TriggerNow(id, opts)
+}
+
+// Backfill() backfills one of the tenant's task definitions, see Scheduler.Backfill()
+func (t *TenantScheduler) Backfill(definitionId *uuid.UUID, from, to time.Time, opts BackfillOptions) ([]TaskInstance, error) {
+	_, err := t.GetTaskDefinition(definitionId)
+	if err != nil {
+		return nil, err
+	}
+	return t.scheduler.Backfill(definitionId, from, to, opts)
+}
+
+// ListTaskInstances() lists the task instances of one of the tenant's task definitions with a fire time between from
+// and to, inclusive
+func (t *TenantScheduler) ListTaskInstances(definitionId *uuid.UUID, from, to time.Time) ([]TaskInstance, error) {
+	_, err := t.GetTaskDefinition(definitionId)
+	if err != nil {
+		return nil, err
+	}
+	return t.scheduler.store.ListTaskInstancesForTaskDefinition(definitionId, from, to)
+}
+
+// GetWorkflowRun() returns a workflow run whose root task definition belongs to the tenant
+func (t *TenantScheduler) GetWorkflowRun(id *uuid.UUID) (WorkflowRun, error) {
+	run, err := t.scheduler.GetWorkflowRun(id)
+	if err != nil {
+		return WorkflowRun{}, err
+	}
+	_, err = t.GetTaskDefinition(run.RootTaskDefinitionId)
+	if err != nil {
+		return WorkflowRun{}, err
+	}
+	return run, nil
+}
+
+func (t *TenantScheduler) ListWorkflowRuns(rootTaskDefinitionId *uuid.UUID, offset, limit int) ([]WorkflowRun, error) {
+	_, err := t.GetTaskDefinition(rootTaskDefinitionId)
+	if err != nil {
+		return nil, err
+	}
+	return t.scheduler.ListWorkflowRuns(rootTaskDefinitionId, offset, limit)
+}
+
+func (t *TenantScheduler) ListWorkflowRunTaskInstances(workflowRunId *uuid.UUID) ([]TaskInstance, error) {
+	_, err := t.GetWorkflowRun(workflowRunId)
+	if err != nil {
+		return nil, err
+	}
+	return t.scheduler.ListWorkflowRunTaskInstances(workflowRunId)
+}
+
+// PauseNamespace() pauses the tenant's task definitions in the namespace
+func (t *TenantScheduler) PauseNamespace(namespace string) error {
+	return t.forEachInNamespace(namespace, func(definition TaskDefinition) error {
+		if definition.Paused {
+			return nil
+		}
+		return t.scheduler.PauseTaskDefinition(definition.Id)
+	})
+}
+
+// ResumeNamespace() resumes the tenant's paused task definitions in the namespace
+func (t *TenantScheduler) ResumeNamespace(namespace string, behavior ResumeBehavior) error {
+	return t.forEachInNamespace(namespace, func(definition TaskDefinition) error {
+		if !definition.Paused {
+			return nil
+		}
+		return t.scheduler.ResumeTaskDefinition(definition.Id, behavior)
+	})
+}
+
+// DeleteNamespace() deletes the tenant's task definitions in the namespace, and their task instances
+func (t *TenantScheduler) DeleteNamespace(namespace string) error {
+	if namespace == "" {
+		return errorx.IllegalArgument.New("a namespace must be provided")
+	}
+	return t.DeleteTaskDefinitions(TaskDefinitionFilter{Namespace: namespace})
+}
+
+// forEachInNamespace() calls fn with each of the tenant's task definitions in the namespace, a page at a time
+func (t *TenantScheduler) forEachInNamespace(namespace string, fn func(definition TaskDefinition) error) error {
+	if namespace == "" {
+		return errorx.IllegalArgument.New("a namespace must be provided")
+	}
+	for offset := 0; ; offset += namespacePageSize {
+		definitions, err := t.ListTaskDefinitions(offset, namespacePageSize, TaskDefinitionFilter{Namespace: namespace})
+		if err != nil {
+			return err
+		}
+		for _, definition := range definitions {
+			err = fn(definition)
+			if err != nil {
+				return err
+			}
+		}
+		if len(definitions) < namespacePageSize {
+			return nil
+		}
+	}
+}
@@ -8,3 +8,24 @@ type TriggerInterface interface {
 	GetFireTime(from time.Time) *time.Time
 	IsRecurring() bool
 }
+
+// CompletionTriggerInterface is implemented by triggers whose next fire time can be computed from when the previous
+// task instance completed instead of from the previous fire time. When FiresAfterCompletion() returns true the
+// scheduler only creates the next task instance once the previous one completes.
+type CompletionTriggerInterface interface {
+	TriggerInterface
+	FiresAfterCompletion() bool
+}
+
+// WindowTriggerInterface is implemented by triggers whose task instances can run any time within a window after the
+// fire time. GetWindow() returns how long the window lasts, and the size of the slots task instances are placed in.
+// The scheduler places each task instance in the window's least loaded slot.
+type WindowTriggerInterface interface {
+	TriggerInterface
+	GetWindow() (time.Duration, time.Duration)
+}
+
+func firesAfterCompletion(trigger TriggerInterface) bool {
+	completionTrigger, ok := trigger.(CompletionTriggerInterface)
+	return ok && completionTrigger.FiresAfterCompletion()
+}
@@ -0,0 +1,50 @@
+package pkg
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+)
+
+type TriggerNowOptions struct {
+	// Metadata overrides the task definition's metadata for the manually triggered task instance, the task definition's
+	// metadata is used if nil
+	Metadata interface{}
+}
+
+// TriggerNow() creates a task instance for the task definition that executes immediately, and dispatches it to the
+// handler. The task instance is marked as manually triggered. The task definition's next fire time, occurrences and
+// completion are left alone, so its schedule isn't disturbed.
+func (s *Scheduler) TriggerNow(definitionId *uuid.UUID, opts TriggerNowOptions) (TaskInstance, error) {
+	if definitionId == nil {
+		return TaskInstance{}, errorx.IllegalArgument.New("an id must be provided")
+	}
+	taskDefinition, err := s.store.GetTaskDefinition(definitionId)
+	if err != nil {
+		return TaskInstance{}, err
+	}
+	id := uuid.New()
+	executeAt := time.Now()
+	expiresAt := executeAt.Add(taskDefinition.ExpireAfter)
+	taskInstance := TaskInstance{
+		Id:             &id,
+		ExpiresAt:      &expiresAt,
+		ExecuteAt:      &executeAt,
+		FireTime:       &executeAt,
+		Manual:         true,
+		Metadata:       opts.Metadata,
+		TenantId:       taskDefinition.TenantId,
+		TaskDefinition: taskDefinition,
+	}
+	err = s.store.UpsertTaskInstance(taskInstance)
+	if err != nil {
+		return TaskInstance{}, err
+	}
+	// the task instance is due now, so the next runner tick would pick it up on any node, dispatching it here runs it
+	// without waiting for that tick
+	if s.run {
+		s.dispatch(taskInstance)
+	}
+	return taskInstance, nil
+}
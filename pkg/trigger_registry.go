@@ -0,0 +1,138 @@
+package pkg
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/joomcode/errorx"
+)
+
+// TriggerMarshalFunc encodes a registered trigger for the store
+type TriggerMarshalFunc func(trigger TriggerInterface) ([]byte, error)
+
+// TriggerUnmarshalFunc decodes a registered trigger encoded by its TriggerMarshalFunc
+type TriggerUnmarshalFunc func(data []byte) (TriggerInterface, error)
+
+type triggerRegistration struct {
+	name        string
+	triggerType reflect.Type
+	marshal     TriggerMarshalFunc
+	unmarshal   TriggerUnmarshalFunc
+}
+
+var triggerRegistry = struct {
+	lock   sync.RWMutex
+	byName map[string]triggerRegistration
+	byType map[reflect.Type]triggerRegistration
+}{
+	byName: map[string]triggerRegistration{},
+	byType: map[reflect.Type]triggerRegistration{},
+}
+
+// RegisterTrigger() registers a custom trigger type under a name, so that task definitions with it can be stored and
+// loaded. example is a value of the trigger type, marshal and unmarshal encode and decode it, and default to JSON when
+// nil. Triggers should be registered before any task definitions with them are loaded, typically in an init function.
+func RegisterTrigger(name string, example TriggerInterface, marshal TriggerMarshalFunc, unmarshal TriggerUnmarshalFunc) error {
+	if name == "" {
+		return errorx.IllegalArgument.New("triggers must be registered with a name")
+	}
+	if example == nil {
+		return errorx.IllegalArgument.New("triggers must be registered with an example")
+	}
+	triggerType := reflect.TypeOf(example)
+	if marshal == nil {
+		marshal = func(trigger TriggerInterface) ([]byte, error) {
+			return json.Marshal(trigger)
+		}
+	}
+	if unmarshal == nil {
+		unmarshal = func(data []byte) (TriggerInterface, error) {
+			return unmarshalJSONTrigger(triggerType, data)
+		}
+	}
+	triggerRegistry.lock.Lock()
+	defer triggerRegistry.lock.Unlock()
+	if existing, ok := triggerRegistry.byName[name]; ok && existing.triggerType != triggerType {
+		return errorx.IllegalArgument.New("%s is already registered as %s", existing.triggerType, name)
+	}
+	registration := triggerRegistration{name: name, triggerType: triggerType, marshal: marshal, unmarshal: unmarshal}
+	triggerRegistry.byName[name] = registration
+	triggerRegistry.byType[triggerType] = registration
+	return nil
+}
+
+// unmarshalJSONTrigger() decodes JSON into a new value of the trigger type, which can be a struct or a pointer to one
+func unmarshalJSONTrigger(triggerType reflect.Type, data []byte) (TriggerInterface, error) {
+	isPointer := triggerType.Kind() == reflect.Pointer
+	elemType := triggerType
+	if isPointer {
+		elemType = triggerType.Elem()
+	}
+	value := reflect.New(elemType)
+	err := json.Unmarshal(data, value.Interface())
+	if err != nil {
+		return nil, err
+	}
+	if !isPointer {
+		value = value.Elem()
+	}
+	return value.Interface().(TriggerInterface), nil
+}
+
+func getTriggerRegistration(trigger TriggerInterface) (triggerRegistration, bool) {
+	triggerRegistry.lock.RLock()
+	defer triggerRegistry.lock.RUnlock()
+	registration, ok := triggerRegistry.byType[reflect.TypeOf(trigger)]
+	return registration, ok
+}
+
+// RegisteredTrigger holds a trigger registered with RegisterTrigger(), it marshals to the trigger's registered name
+// and the data encoded by its marshal function
+type RegisteredTrigger struct {
+	TriggerInterface
+}
+
+func NewRegisteredTrigger(trigger TriggerInterface) (*RegisteredTrigger, error) {
+	if _, ok := getTriggerRegistration(trigger); !ok {
+		return nil, errorx.IllegalArgument.New("trigger type %T isn't registered", trigger)
+	}
+	return &RegisteredTrigger{TriggerInterface: trigger}, nil
+}
+
+type registeredTriggerJson struct {
+	Type string `json:"type"`
+	Data []byte `json:"data"`
+}
+
+func (t RegisteredTrigger) MarshalJSON() ([]byte, error) {
+	registration, ok := getTriggerRegistration(t.TriggerInterface)
+	if !ok {
+		return nil, errorx.IllegalArgument.New("trigger type %T isn't registered", t.TriggerInterface)
+	}
+	data, err := registration.marshal(t.TriggerInterface)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(registeredTriggerJson{Type: registration.name, Data: data})
+}
+
+func (t *RegisteredTrigger) UnmarshalJSON(bytes []byte) error {
+	encoded := registeredTriggerJson{}
+	err := json.Unmarshal(bytes, &encoded)
+	if err != nil {
+		return err
+	}
+	triggerRegistry.lock.RLock()
+	registration, ok := triggerRegistry.byName[encoded.Type]
+	triggerRegistry.lock.RUnlock()
+	if !ok {
+		return errorx.IllegalArgument.New("trigger type %s isn't registered", encoded.Type)
+	}
+	trigger, err := registration.unmarshal(encoded.Data)
+	if err != nil {
+		return err
+	}
+	t.TriggerInterface = trigger
+	return nil
+}
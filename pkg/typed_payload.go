@@ -0,0 +1,79 @@
+package pkg
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/joomcode/errorx"
+)
+
+// PayloadTyper can be implemented by payload types to name themselves. Payload types are otherwise named after their
+// package path and type name, which changes if the type is renamed or moved, orphaning stored task definitions.
+type PayloadTyper interface {
+	PayloadType() string
+}
+
+// PayloadTypeName() returns the name task definitions with a payload of type T are stored with
+func PayloadTypeName[T any]() string {
+	var payload T
+	if typer, ok := any(payload).(PayloadTyper); ok {
+		return typer.PayloadType()
+	}
+	payloadType := reflect.TypeOf((*T)(nil)).Elem()
+	if payloadType.PkgPath() == "" {
+		return payloadType.String()
+	}
+	return payloadType.PkgPath() + "." + payloadType.Name()
+}
+
+// TypedTask is a task definition with a payload of type T. The payload is stored separately from the task definition's
+// metadata, metadata stays searchable and the payload is decoded back into T for the handler.
+type TypedTask[T any] struct {
+	TaskDefinition
+	Payload T
+}
+
+func NewTypedTask[T any](definition TaskDefinition, payload T) TypedTask[T] {
+	return TypedTask[T]{TaskDefinition: definition, Payload: payload}
+}
+
+// ToTaskDefinition() returns the task definition with the payload encoded, ready to upsert
+func (t TypedTask[T]) ToTaskDefinition() (TaskDefinition, error) {
+	payload, err := json.Marshal(t.Payload)
+	if err != nil {
+		return TaskDefinition{}, errorx.IllegalArgument.Wrap(err, "error encoding payload")
+	}
+	definition := t.TaskDefinition
+	definition.PayloadType = PayloadTypeName[T]()
+	definition.Payload = payload
+	// the payload is plaintext until it's encrypted again
+	definition.PayloadEnvelope = nil
+	return definition, nil
+}
+
+// DecodePayload() decodes the task instance's payload into T
+func DecodePayload[T any](taskInstance TaskInstance) (T, error) {
+	var payload T
+	payloadType := PayloadTypeName[T]()
+	if taskInstance.TaskDefinition.PayloadType != payloadType {
+		return payload, errorx.IllegalArgument.New("task definition's payload is a %s, not a %s", taskInstance.TaskDefinition.PayloadType, payloadType)
+	}
+	err := json.Unmarshal(taskInstance.TaskDefinition.Payload, &payload)
+	if err != nil {
+		return payload, errorx.IllegalArgument.Wrap(err, "error decoding %s payload", payloadType)
+	}
+	return payload, nil
+}
+
+// RegisterTypedHandler() routes task instances whose task definitions have a payload of type T to the handler, with
+// the payload decoded. Payloads that can't be decoded fail the task instance permanently. Typed handlers take
+// precedence over namespace handlers. Register typed handlers before calling Run().
+func RegisterTypedHandler[T any](s *Scheduler, handler func(taskInstance TaskInstance, payload T) error) {
+	s.typedHandlers[PayloadTypeName[T]()] = func(taskInstance TaskInstance) error {
+		payload, err := DecodePayload[T](taskInstance)
+		if err != nil {
+			return PermanentFailure(err)
+		}
+		return handler(taskInstance, payload)
+	}
+}
@@ -0,0 +1,163 @@
+package pkg
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"time"
+
+	"github.com/catalystsquad/app-utils-go/logging"
+	"github.com/joomcode/errorx"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultWindowSlot = 1 * time.Minute
+	// maxWindowSlots caps the number of slots a window is split into, so placing a task instance stays cheap
+	maxWindowSlots = 10000
+)
+
+// WindowTrigger fires once per window, for tasks that only need to run "sometime between 1am and 5am". Windows open on
+// a cron expression and stay open for Duration. The scheduler splits the window into slots, and places each task
+// instance in the slot with the fewest task instances already scheduled, FireTime on the task instance is the start of
+// the window.
+type WindowTrigger struct {
+	// Expression is the cron expression windows open on
+	Expression string `json:"expression"`
+	// Location is the IANA time zone name the expression is evaluated in, UTC if empty
+	Location string        `json:"location"`
+	Duration time.Duration `json:"duration"`
+	// Slot is the granularity task instances are placed at within the window, defaults to a minute
+	Slot time.Duration `json:"slot"`
+	cron *CronTrigger
+}
+
+// GetFireTime() returns the start of the first window that opens after from
+func (t WindowTrigger) GetFireTime(from time.Time) *time.Time {
+	cron, err := t.getCron()
+	if err != nil {
+		return nil
+	}
+	return cron.GetFireTime(from)
+}
+
+func (t WindowTrigger) IsRecurring() bool {
+	return true
+}
+
+func (t WindowTrigger) GetWindow() (time.Duration, time.Duration) {
+	slot := t.Slot
+	if slot <= 0 {
+		slot = defaultWindowSlot
+	}
+	return t.Duration, slot
+}
+
+// UnmarshalJSON() rebuilds the parsed expression, which isn't marshalled
+func (t *WindowTrigger) UnmarshalJSON(bytes []byte) error {
+	type windowTriggerJson WindowTrigger
+	unmarshalled := windowTriggerJson{}
+	err := json.Unmarshal(bytes, &unmarshalled)
+	if err != nil {
+		return err
+	}
+	*t = WindowTrigger(unmarshalled)
+	t.cron, err = t.getCron()
+	return err
+}
+
+// In() returns a copy of the trigger evaluated in the given location
+func (t WindowTrigger) In(location *time.Location) *WindowTrigger {
+	t.Location = location.String()
+	t.cron = nil
+	t.cron, _ = t.getCron()
+	return &t
+}
+
+// getCron() returns the parsed expression, parsing it if the trigger was constructed without NewWindowTrigger()
+func (t WindowTrigger) getCron() (*CronTrigger, error) {
+	if t.cron != nil {
+		return t.cron, nil
+	}
+	location, err := time.LoadLocation(t.Location)
+	if err != nil {
+		return nil, err
+	}
+	return NewCronTriggerWithLocation(t.Expression, location)
+}
+
+// NewWindowTrigger() returns a trigger whose windows open on the cron expression and last for duration, task instances
+// are placed at the granularity of slot, a minute if 0. A nil location uses the scheduler's default location.
+func NewWindowTrigger(cronExpression string, duration, slot time.Duration, location *time.Location) (*WindowTrigger, error) {
+	trigger := &WindowTrigger{Expression: cronExpression, Duration: duration, Slot: slot}
+	err := trigger.validate()
+	if err != nil {
+		return nil, err
+	}
+	cron, err := NewCronTriggerWithLocation(cronExpression, location)
+	if err != nil {
+		return nil, errorx.IllegalArgument.Wrap(err, "invalid window cron expression: %s", cronExpression)
+	}
+	trigger.cron = cron
+	trigger.Location = cron.Location
+	return trigger, nil
+}
+
+// validate() checks the window's duration and slot, task definitions are validated with it too because triggers can
+// be built without NewWindowTrigger()
+func (t WindowTrigger) validate() error {
+	if t.Duration <= 0 {
+		return errorx.IllegalArgument.New("window duration must be positive")
+	}
+	if t.Slot < 0 || t.Slot > t.Duration {
+		return errorx.IllegalArgument.New("window slot must be positive and at most the window's duration")
+	}
+	if window, slot := t.GetWindow(); window/slot > maxWindowSlots {
+		return errorx.IllegalArgument.New("windows can have at most %d slots", maxWindowSlots)
+	}
+	return nil
+}
+
+// placeInWindow() returns when a task instance for the fire time should execute. For window triggers that's the start
+// of the least loaded slot of the window that hasn't passed, ties are broken by the task definition's id so that task
+// definitions placed at the same time don't all pick the same slot. Other triggers execute at the fire time.
+func (s *Scheduler) placeInWindow(taskDefinition TaskDefinition, fireTime time.Time) time.Time {
+	windowTrigger, ok := taskDefinition.GetTrigger().(WindowTriggerInterface)
+	if !ok {
+		return fireTime
+	}
+	window, slot := windowTrigger.GetWindow()
+	if slot <= 0 || window/slot > maxWindowSlots {
+		return fireTime
+	}
+	slots := int(window / slot)
+	first := 0
+	if elapsed := time.Since(fireTime); elapsed > 0 {
+		first = int((elapsed + slot - 1) / slot)
+	}
+	if first >= slots {
+		// the window has passed, run as soon as possible
+		return fireTime
+	}
+	from := fireTime.Add(time.Duration(first) * slot)
+	counts, err := s.store.CountTaskInstancesInSlots(from, slot, slots-first)
+	if err != nil {
+		logging.Log.WithError(err).WithFields(logrus.Fields{"task_definition_id": taskDefinition.Id}).Error("error counting task instances in window, placing the task instance at the start of the window")
+		return from
+	}
+	leastLoaded := []int{}
+	for i, count := range counts {
+		if len(leastLoaded) == 0 || count < counts[leastLoaded[0]] {
+			leastLoaded = []int{i}
+		} else if count == counts[leastLoaded[0]] {
+			leastLoaded = append(leastLoaded, i)
+		}
+	}
+	if len(leastLoaded) == 0 {
+		return from
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(taskDefinition.IdString()))
+	hash.Write([]byte(fireTime.UTC().String()))
+	chosen := leastLoaded[hash.Sum64()%uint64(len(leastLoaded))]
+	return from.Add(time.Duration(chosen) * slot)
+}
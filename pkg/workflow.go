@@ -0,0 +1,307 @@
+package pkg
+
+import (
+	"time"
+
+	"github.com/catalystsquad/app-utils-go/logging"
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+	"github.com/sirupsen/logrus"
+)
+
+// FailurePolicy controls what happens to a task definition with dependencies when one of its dependencies fails, or is
+// skipped, in a workflow run
+type FailurePolicy string
+
+const (
+	// FailurePolicySkip skips the task definition, its task instance is marked as skipped without running and the skip
+	// propagates to the task definitions that depend on it. This is the default.
+	FailurePolicySkip FailurePolicy = "skip"
+	// FailurePolicyFail fails the workflow run, no more task instances are created for it
+	FailurePolicyFail FailurePolicy = "fail"
+	// FailurePolicyContinue runs the task definition anyway
+	FailurePolicyContinue FailurePolicy = "continue"
+)
+
+func (p FailurePolicy) IsValid() bool {
+	switch p {
+	case "", FailurePolicySkip, FailurePolicyFail, FailurePolicyContinue:
+		return true
+	}
+	return false
+}
+
+type WorkflowRunStatus string
+
+const (
+	WorkflowRunStatusRunning   WorkflowRunStatus = "running"
+	WorkflowRunStatusSucceeded WorkflowRunStatus = "succeeded"
+	WorkflowRunStatusFailed    WorkflowRunStatus = "failed"
+)
+
+// WorkflowRun is a single run of a workflow. A run starts when a task instance of a root task definition, one without
+// dependencies that other task definitions depend on, completes. Task instances of the task definitions that depend on
+// it are created for the run as their dependencies complete.
+type WorkflowRun struct {
+	Id                   *uuid.UUID `json:"id"`
+	RootTaskDefinitionId *uuid.UUID `json:"root_task_definition_id"`
+	// FireTime is the fire time of the root task instance, every task instance in the run has the same fire time
+	FireTime    *time.Time        `json:"fire_time"`
+	Status      WorkflowRunStatus `json:"status"`
+	StartedAt   *time.Time        `json:"started_at"`
+	CompletedAt *time.Time        `json:"completed_at"`
+}
+
+// workflowRunNamespace derives deterministic ids for workflow runs and their task instances, so that nodes that
+// evaluate a run at the same time create the same records instead of duplicates
+var workflowRunNamespace = uuid.MustParse("5a0d2f8e-3c1b-4e7a-9b6d-2f4c8e1a7d30")
+
+type dependencyOutcome int
+
+const (
+	// dependenciesPending means some dependencies haven't completed yet
+	dependenciesPending dependencyOutcome = iota
+	// dependenciesRun means the task definition should run
+	dependenciesRun
+	// dependenciesSkip means the task definition should be skipped
+	dependenciesSkip
+	// dependenciesFailRun means the workflow run should fail
+	dependenciesFailRun
+)
+
+// evaluateDependencies() decides what happens to a task definition in a workflow run, given the run's task instances
+// keyed by task definition id
+func evaluateDependencies(taskDefinition TaskDefinition, runTaskInstances map[uuid.UUID]TaskInstance) dependencyOutcome {
+	succeeded := true
+	for _, dependencyId := range taskDefinition.DependsOn {
+		taskInstance, ok := runTaskInstances[*dependencyId]
+		if !ok || taskInstance.CompletedAt == nil {
+			return dependenciesPending
+		}
+		if taskInstance.FailedAt != nil || taskInstance.Skipped {
+			succeeded = false
+		}
+	}
+	if succeeded {
+		return dependenciesRun
+	}
+	switch taskDefinition.FailurePolicy {
+	case FailurePolicyFail:
+		return dependenciesFailRun
+	case FailurePolicyContinue:
+		return dependenciesRun
+	default:
+		return dependenciesSkip
+	}
+}
+
+func (s *Scheduler) GetWorkflowRun(id *uuid.UUID) (WorkflowRun, error) {
+	if id == nil {
+		return WorkflowRun{}, errorx.IllegalArgument.New("an id must be provided")
+	}
+	return s.store.GetWorkflowRun(id)
+}
+
+// ListWorkflowRuns() lists the workflow runs started by the root task definition, most recent first
+func (s *Scheduler) ListWorkflowRuns(rootTaskDefinitionId *uuid.UUID, offset, limit int) ([]WorkflowRun, error) {
+	return s.store.ListWorkflowRuns(rootTaskDefinitionId, offset, limit)
+}
+
+// ListWorkflowRunTaskInstances() lists the task instances of a workflow run
+func (s *Scheduler) ListWorkflowRunTaskInstances(workflowRunId *uuid.UUID) ([]TaskInstance, error) {
+	return s.store.ListTaskInstancesForWorkflowRun(workflowRunId)
+}
+
+// validateDependencies() checks that the task definition's dependencies exist, don't form a cycle, and all descend
+// from a single root task definition
+func (s *Scheduler) validateDependencies(task TaskDefinition) error {
+	if len(task.DependsOn) == 0 {
+		return nil
+	}
+	visited := map[uuid.UUID]bool{}
+	roots := map[uuid.UUID]bool{}
+	ids := task.DependsOn
+	for len(ids) > 0 {
+		toFetch := []*uuid.UUID{}
+		for _, id := range ids {
+			if id == nil {
+				return errorx.IllegalArgument.New("dependencies must have an id")
+			}
+			if *id == *task.Id {
+				return errorx.IllegalArgument.New("task definition %s depends on itself", task.Id)
+			}
+			if !visited[*id] {
+				visited[*id] = true
+				toFetch = append(toFetch, id)
+			}
+		}
+		if len(toFetch) == 0 {
+			break
+		}
+		dependencies, err := s.store.GetTaskDefinitions(toFetch)
+		if err != nil {
+			return err
+		}
+		if len(dependencies) != len(toFetch) {
+			return errorx.IllegalArgument.New("task definition %s depends on task definitions that don't exist", task.Id)
+		}
+		ids = []*uuid.UUID{}
+		for _, dependency := range dependencies {
+			if len(dependency.DependsOn) == 0 {
+				roots[*dependency.Id] = true
+			}
+			ids = append(ids, dependency.DependsOn...)
+		}
+	}
+	if len(roots) != 1 {
+		return errorx.IllegalArgument.New("task definition %s must descend from a single root task definition", task.Id)
+	}
+	return nil
+}
+
+// advanceWorkflow() is called when a task instance completes or fails, it starts a workflow run if the task instance's
+// task definition has task definitions that depend on it, creates the task instances whose dependencies are done, and
+// completes the run once all of its task instances are done
+func (s *Scheduler) advanceWorkflow(taskInstance TaskInstance) {
+	err := s.evaluateWorkflow(taskInstance)
+	if err != nil {
+		logging.Log.WithError(err).WithFields(logrus.Fields{"task_instance_id": taskInstance.Id, "workflow_run_id": taskInstance.WorkflowRunId}).Error("error advancing workflow run")
+	}
+}
+
+func (s *Scheduler) evaluateWorkflow(taskInstance TaskInstance) error {
+	// manually triggered task instances don't start workflow runs
+	if taskInstance.Manual {
+		return nil
+	}
+	run, err := s.getWorkflowRun(taskInstance)
+	if err != nil || run == nil || run.Status != WorkflowRunStatusRunning {
+		return err
+	}
+	done := []TaskInstance{taskInstance}
+	for len(done) > 0 {
+		current := done[0]
+		done = done[1:]
+		dependents, err := s.store.ListDependentTaskDefinitions(current.TaskDefinition.Id)
+		if err != nil {
+			return err
+		}
+		runTaskInstances, err := s.getWorkflowRunTaskInstances(run.Id)
+		if err != nil {
+			return err
+		}
+		for _, dependent := range dependents {
+			if _, ok := runTaskInstances[*dependent.Id]; ok {
+				continue
+			}
+			switch evaluateDependencies(dependent, runTaskInstances) {
+			case dependenciesRun:
+				_, err = s.createWorkflowTaskInstance(*run, dependent, false)
+			case dependenciesSkip:
+				var skipped *TaskInstance
+				skipped, err = s.createWorkflowTaskInstance(*run, dependent, true)
+				if skipped != nil {
+					// skipped task instances are done without running, so their dependents are evaluated now
+					done = append(done, *skipped)
+				}
+			case dependenciesFailRun:
+				return s.completeWorkflowRun(*run, WorkflowRunStatusFailed)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	runTaskInstances, err := s.getWorkflowRunTaskInstances(run.Id)
+	if err != nil {
+		return err
+	}
+	status := WorkflowRunStatusSucceeded
+	for _, runTaskInstance := range runTaskInstances {
+		if runTaskInstance.CompletedAt == nil {
+			return nil
+		}
+		if runTaskInstance.FailedAt != nil {
+			status = WorkflowRunStatusFailed
+		}
+	}
+	return s.completeWorkflowRun(*run, status)
+}
+
+// getWorkflowRun() returns the task instance's workflow run, starting one if the task instance's task definition is a
+// root with dependents. Returns nil if the task instance isn't part of a workflow.
+func (s *Scheduler) getWorkflowRun(taskInstance TaskInstance) (*WorkflowRun, error) {
+	if taskInstance.WorkflowRunId != nil {
+		run, err := s.store.GetWorkflowRun(taskInstance.WorkflowRunId)
+		return &run, err
+	}
+	if len(taskInstance.TaskDefinition.DependsOn) > 0 {
+		return nil, nil
+	}
+	dependents, err := s.store.ListDependentTaskDefinitions(taskInstance.TaskDefinition.Id)
+	if err != nil || len(dependents) == 0 {
+		return nil, err
+	}
+	id := uuid.NewSHA1(workflowRunNamespace, taskInstance.Id[:])
+	startedAt := time.Now()
+	if taskInstance.StartedAt != nil {
+		startedAt = *taskInstance.StartedAt
+	}
+	run := WorkflowRun{
+		Id:                   &id,
+		RootTaskDefinitionId: taskInstance.TaskDefinition.Id,
+		FireTime:             taskInstance.FireTime,
+		Status:               WorkflowRunStatusRunning,
+		StartedAt:            &startedAt,
+	}
+	err = s.store.StartWorkflowRun(run, taskInstance.Id)
+	return &run, err
+}
+
+func (s *Scheduler) getWorkflowRunTaskInstances(runId *uuid.UUID) (map[uuid.UUID]TaskInstance, error) {
+	taskInstances, err := s.store.ListTaskInstancesForWorkflowRun(runId)
+	if err != nil {
+		return nil, err
+	}
+	byTaskDefinition := map[uuid.UUID]TaskInstance{}
+	for _, taskInstance := range taskInstances {
+		byTaskDefinition[*taskInstance.TaskDefinition.Id] = taskInstance
+	}
+	return byTaskDefinition, nil
+}
+
+// createWorkflowTaskInstance() creates the task definition's task instance for the workflow run, executing now, or
+// completed without running if it's skipped. Returns nil if another node already created it.
+func (s *Scheduler) createWorkflowTaskInstance(run WorkflowRun, taskDefinition TaskDefinition, skipped bool) (*TaskInstance, error) {
+	id := uuid.NewSHA1(*run.Id, taskDefinition.Id[:])
+	executeAt := time.Now()
+	expiresAt := executeAt.Add(taskDefinition.ExpireAfter)
+	taskInstance := TaskInstance{
+		Id:             &id,
+		ExpiresAt:      &expiresAt,
+		ExecuteAt:      &executeAt,
+		FireTime:       run.FireTime,
+		WorkflowRunId:  run.Id,
+		Skipped:        skipped,
+		TenantId:       taskDefinition.TenantId,
+		TaskDefinition: taskDefinition,
+	}
+	if skipped {
+		taskInstance.CompletedAt = &executeAt
+	}
+	created, err := s.store.InsertTaskInstance(taskInstance)
+	if err != nil || !created {
+		return nil, err
+	}
+	if !skipped && s.run {
+		s.dispatch(taskInstance)
+	}
+	return &taskInstance, nil
+}
+
+func (s *Scheduler) completeWorkflowRun(run WorkflowRun, status WorkflowRunStatus) error {
+	completedAt := time.Now()
+	run.Status = status
+	run.CompletedAt = &completedAt
+	return s.store.UpsertWorkflowRun(run)
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCalendarExcludes(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	calendar := pkg.Calendar{
+		Name:     "support",
+		Location: "America/Chicago",
+		Dates:    []string{"2026-12-25"},
+		// sunday night maintenance
+		WeeklyWindows: []pkg.WeeklyWindow{{Weekday: time.Sunday, Start: "22:00", Duration: 4 * time.Hour}},
+		Ranges:        []pkg.TimeRange{{Start: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)}},
+	}
+	require.NoError(t, calendar.Validate())
+	// christmas in chicago, not in UTC
+	require.True(t, calendar.Excludes(time.Date(2026, 12, 25, 23, 0, 0, 0, chicago)))
+	require.False(t, calendar.Excludes(time.Date(2026, 12, 25, 3, 0, 0, 0, time.UTC)))
+	// the maintenance window spans midnight
+	require.True(t, calendar.Excludes(time.Date(2026, 10, 25, 23, 0, 0, 0, chicago)))
+	require.True(t, calendar.Excludes(time.Date(2026, 10, 26, 1, 59, 0, 0, chicago)))
+	require.False(t, calendar.Excludes(time.Date(2026, 10, 26, 2, 0, 0, 0, chicago)))
+	// the range includes its start but not its end
+	require.True(t, calendar.Excludes(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)))
+	require.False(t, calendar.Excludes(time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestCalendarPolicies(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	calendar := &pkg.Calendar{Name: "holidays", Location: "America/Chicago", Dates: []string{"2026-12-24", "2026-12-25"}}
+	// 9am daily
+	trigger, err := pkg.NewCronTriggerWithLocation("0 0 9 * * * *", chicago)
+	require.NoError(t, err)
+	from := time.Date(2026, 12, 23, 12, 0, 0, 0, chicago)
+	// skipped fire times move to the next fire time after the holidays
+	skip := pkg.TaskDefinition{CronTrigger: trigger, CalendarPolicy: pkg.CalendarPolicySkip}.WithCalendar(calendar)
+	require.Equal(t, time.Date(2026, 12, 26, 9, 0, 0, 0, chicago), skip.GetFireTimeFrom(from).In(chicago))
+	// deferred fire times fire once when the holidays end
+	deferred := pkg.TaskDefinition{CronTrigger: trigger, CalendarPolicy: pkg.CalendarPolicyDefer}.WithCalendar(calendar)
+	fireTime := deferred.GetFireTimeFrom(from)
+	require.Equal(t, time.Date(2026, 12, 26, 0, 0, 0, 0, chicago), fireTime.In(chicago))
+	require.Equal(t, time.Date(2026, 12, 26, 9, 0, 0, 0, chicago), deferred.GetFireTimeFrom(*fireTime).In(chicago))
+	// one time tasks are always deferred
+	once := pkg.TaskDefinition{ExecuteOnceTrigger: pkg.NewExecuteOnceTrigger(time.Date(2026, 12, 24, 9, 0, 0, 0, chicago))}.WithCalendar(calendar)
+	require.Equal(t, time.Date(2026, 12, 26, 0, 0, 0, 0, chicago), once.GetFireTimeFrom(from).In(chicago))
+}
+
+func TestCalendarValidation(t *testing.T) {
+	for _, calendar := range []pkg.Calendar{
+		{},
+		{Name: "bad location", Location: "Nowhere/Special"},
+		{Name: "bad date", Dates: []string{"12/25/2026"}},
+		{Name: "bad window start", WeeklyWindows: []pkg.WeeklyWindow{{Weekday: time.Sunday, Start: "10pm", Duration: time.Hour}}},
+		{Name: "bad window duration", WeeklyWindows: []pkg.WeeklyWindow{{Weekday: time.Sunday, Start: "22:00"}}},
+		{Name: "bad range", Ranges: []pkg.TimeRange{{Start: time.Now(), End: time.Now().Add(-time.Hour)}}},
+	} {
+		require.Error(t, calendar.Validate(), calendar.Name)
+	}
+}
@@ -106,3 +106,103 @@ func (s *CockroachdbStoreSuite) TestSingleTaskDefinitionCreatedForCronTasks() {
 	require.NoError(s.T(), err)
 	require.Len(s.T(), definitions, 1)
 }
+
+func (s *CockroachdbStoreSuite) TestLeaderElection() {
+	TestLeaderElection(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestNodeRegistry() {
+	TestNodeRegistry(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestSharding() {
+	TestSharding(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestImmediatePickup() {
+	TestImmediatePickup(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestMisfirePolicies() {
+	TestMisfirePolicies(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestBackfill() {
+	TestBackfill(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestIntervalTriggerModes() {
+	TestIntervalTriggerModes(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestNaturalLanguageTriggerPersistence() {
+	TestNaturalLanguageTriggerPersistence(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestRRuleTriggerPersistence() {
+	TestRRuleTriggerPersistence(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestTriggerBounds() {
+	TestTriggerBounds(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestCalendars() {
+	TestCalendars(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestJitter() {
+	TestJitter(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestRegisteredTriggerPersistence() {
+	TestRegisteredTriggerPersistence(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestTriggerNow() {
+	TestTriggerNow(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestWorkflows() {
+	TestWorkflows(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestHandlerDirectives() {
+	TestHandlerDirectives(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestWindowTriggerPlacement() {
+	TestWindowTriggerPlacement(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestPauseResume() {
+	TestPauseResume(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestNamespaces() {
+	TestNamespaces(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestTenants() {
+	TestTenants(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestTaskDefinitionKeys() {
+	TestTaskDefinitionKeys(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestOptimisticConcurrency() {
+	TestOptimisticConcurrency(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestScheduleVersionConflicts() {
+	TestScheduleVersionConflicts(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestTypedPayloads() {
+	TestTypedPayloads(s.T(), cockroachdbStore)
+}
+
+func (s *CockroachdbStoreSuite) TestPayloadEncryption() {
+	TestPayloadEncryption(s.T(), cockroachdbStore)
+}
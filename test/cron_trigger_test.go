@@ -0,0 +1,72 @@
+package test
+
+import (
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCronTriggerLocation(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	// 9am on weekdays
+	trigger, err := pkg.NewCronTriggerWithLocation("0 0 9 * * 1-5 *", chicago)
+	require.NoError(t, err)
+	// friday before the spring forward transition, fires at 9am CST
+	fireTime := trigger.GetFireTime(time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC))
+	require.Equal(t, time.Date(2026, 3, 6, 15, 0, 0, 0, time.UTC), fireTime.UTC())
+	// monday after the transition, still fires at 9am which is now CDT
+	fireTime = trigger.GetFireTime(*fireTime)
+	require.Equal(t, time.Date(2026, 3, 9, 14, 0, 0, 0, time.UTC), fireTime.UTC())
+}
+
+func TestCronTriggerExhausted(t *testing.T) {
+	// the year has passed, so the expression never fires again
+	trigger, err := pkg.NewCronTrigger("0 0 9 * * * 2020")
+	require.NoError(t, err)
+	require.Nil(t, trigger.GetFireTime(time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)))
+	// and neither does a task definition with it
+	require.Nil(t, pkg.TaskDefinition{CronTrigger: trigger}.GetFireTimeFrom(time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestCronTriggerDefaultsToUTC(t *testing.T) {
+	trigger, err := pkg.NewCronTrigger("0 0 9 * * * *")
+	require.NoError(t, err)
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	// the location of from doesn't matter
+	fireTime := trigger.GetFireTime(time.Date(2026, 3, 6, 0, 0, 0, 0, chicago))
+	require.Equal(t, time.Date(2026, 3, 6, 9, 0, 0, 0, time.UTC), fireTime.UTC())
+}
+
+func TestCronTriggerSkippedWallClockTime(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	// 2:30am daily, which doesn't exist on 2026-03-08 in Chicago
+	trigger, err := pkg.NewCronTriggerWithLocation("0 30 2 * * * *", chicago)
+	require.NoError(t, err)
+	fireTime := trigger.GetFireTime(time.Date(2026, 3, 7, 12, 0, 0, 0, chicago))
+	// fires when the clocks jump from 2am to 3am CDT
+	require.Equal(t, time.Date(2026, 3, 8, 3, 0, 0, 0, chicago), *fireTime)
+	// and at 2:30am the day after
+	fireTime = trigger.GetFireTime(*fireTime)
+	require.Equal(t, time.Date(2026, 3, 9, 2, 30, 0, 0, chicago), *fireTime)
+}
+
+func TestCronTriggerRepeatedWallClockTime(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	// 1:30am daily, which happens twice on 2026-11-01 in Chicago
+	trigger, err := pkg.NewCronTriggerWithLocation("0 30 1 * * * *", chicago)
+	require.NoError(t, err)
+	fireTime := trigger.GetFireTime(time.Date(2026, 10, 31, 12, 0, 0, 0, chicago))
+	// fires the first time, in CDT
+	require.Equal(t, time.Date(2026, 11, 1, 6, 30, 0, 0, time.UTC), fireTime.UTC())
+	// and not again an hour later in CST
+	nextFireTime := trigger.GetFireTime(*fireTime)
+	require.Equal(t, time.Date(2026, 11, 2, 7, 30, 0, 0, time.UTC), nextFireTime.UTC())
+	// from within the repeated hour the first occurrence has passed
+	fromRepeat := time.Date(2026, 11, 1, 7, 15, 0, 0, time.UTC)
+	require.Equal(t, *nextFireTime, *trigger.GetFireTime(fromRepeat))
+}
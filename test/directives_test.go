@@ -0,0 +1,22 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDirectivesCanBeWrapped(t *testing.T) {
+	err := fmt.Errorf("user is offline: %w", pkg.RetryAfter(2*time.Hour))
+	var retryAfter pkg.RetryAfterError
+	require.True(t, errors.As(err, &retryAfter))
+	require.Equal(t, 2*time.Hour, retryAfter.Delay)
+	cause := errors.New("recipient doesn't exist")
+	err = pkg.PermanentFailure(cause)
+	var permanentFailure pkg.PermanentFailureError
+	require.True(t, errors.As(err, &permanentFailure))
+	require.ErrorIs(t, err, cause)
+}
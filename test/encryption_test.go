@@ -0,0 +1,102 @@
+package test
+
+import (
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestStaticKeyProviderValidatesKeys(t *testing.T) {
+	_, err := pkg.NewStaticKeyProvider("missing", map[string][]byte{"old": oldKey})
+	require.Error(t, err)
+	_, err = pkg.NewStaticKeyProvider("short", map[string][]byte{"short": []byte("too short")})
+	require.Error(t, err)
+}
+
+func TestPayloadEncryptionRoundTrip(t *testing.T) {
+	provider, err := pkg.NewStaticKeyProvider("old", map[string][]byte{"old": oldKey})
+	require.NoError(t, err)
+	id := uuid.New()
+	plaintext := []byte(`{"phone":"+15555550100"}`)
+	encrypted, err := pkg.EncryptPayload(provider, pkg.TaskDefinition{Id: &id, Payload: plaintext})
+	require.NoError(t, err)
+	require.NotNil(t, encrypted.PayloadEnvelope)
+	require.Equal(t, "old", encrypted.PayloadEnvelope.KeyId)
+	require.NotContains(t, string(encrypted.Payload), "+15555550100")
+	// encrypting again leaves it alone
+	again, err := pkg.EncryptPayload(provider, encrypted)
+	require.NoError(t, err)
+	require.Equal(t, encrypted.Payload, again.Payload)
+	decrypted, err := pkg.DecryptPayload(provider, encrypted)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted.Payload)
+	require.Nil(t, decrypted.PayloadEnvelope)
+	// after rotating, payloads wrapped with the old key can still be decrypted
+	rotated, err := pkg.NewStaticKeyProvider("new", map[string][]byte{"old": oldKey, "new": newKey})
+	require.NoError(t, err)
+	decrypted, err = pkg.DecryptPayload(rotated, encrypted)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted.Payload)
+	// the payload is bound to its task definition
+	otherId := uuid.New()
+	moved := encrypted
+	moved.Id = &otherId
+	_, err = pkg.DecryptPayload(provider, moved)
+	require.True(t, errorx.IsOfType(err, pkg.DecryptionFailed))
+	// and can't be decrypted without the key its data key was wrapped with
+	newOnly, err := pkg.NewStaticKeyProvider("new", map[string][]byte{"new": newKey})
+	require.NoError(t, err)
+	_, err = pkg.DecryptPayload(newOnly, encrypted)
+	require.True(t, errorx.IsOfType(err, pkg.DecryptionFailed))
+}
+
+func TestPayloadEncryptionOfEditedPayloads(t *testing.T) {
+	provider, err := pkg.NewStaticKeyProvider("old", map[string][]byte{"old": oldKey})
+	require.NoError(t, err)
+	id := uuid.New()
+	encrypted, err := pkg.EncryptPayload(provider, pkg.TaskDefinition{Id: &id, Payload: []byte(`{"phone":"+15555550100"}`)})
+	require.NoError(t, err)
+	// a task definition read from the scheduler keeps its envelope when its payload is replaced
+	edited := encrypted
+	edited.Payload = []byte(`{"phone":"+15555550199"}`)
+	reencrypted, err := pkg.EncryptPayload(provider, edited)
+	require.NoError(t, err)
+	require.NotContains(t, string(reencrypted.Payload), "+15555550199")
+	require.NotEqual(t, encrypted.PayloadEnvelope.WrappedKey, reencrypted.PayloadEnvelope.WrappedKey)
+	decrypted, err := pkg.DecryptPayload(provider, reencrypted)
+	require.NoError(t, err)
+	require.Equal(t, []byte(`{"phone":"+15555550199"}`), decrypted.Payload)
+	// clearing the payload drops the envelope
+	cleared := encrypted
+	cleared.Payload = nil
+	cleared, err = pkg.EncryptPayload(provider, cleared)
+	require.NoError(t, err)
+	require.Nil(t, cleared.PayloadEnvelope)
+	// an envelope whose key is gone can't be checked, so it isn't encrypted again
+	newOnly, err := pkg.NewStaticKeyProvider("new", map[string][]byte{"new": newKey})
+	require.NoError(t, err)
+	_, err = pkg.EncryptPayload(newOnly, edited)
+	require.True(t, errorx.IsOfType(err, pkg.DecryptionFailed))
+}
+
+type unavailableKeyProvider struct {
+	pkg.StaticKeyProvider
+}
+
+func (p *unavailableKeyProvider) UnwrapKey(keyId string, wrappedKey []byte) ([]byte, error) {
+	return nil, errorx.ExternalError.New("key service unavailable")
+}
+
+func TestPayloadDecryptionErrorsFromKeyProviderAreKept(t *testing.T) {
+	provider, err := pkg.NewStaticKeyProvider("old", map[string][]byte{"old": oldKey})
+	require.NoError(t, err)
+	id := uuid.New()
+	encrypted, err := pkg.EncryptPayload(provider, pkg.TaskDefinition{Id: &id, Payload: []byte(`{}`)})
+	require.NoError(t, err)
+	// transport errors aren't decryption failures, so they're retried
+	_, err = pkg.DecryptPayload(&unavailableKeyProvider{StaticKeyProvider: *provider}, encrypted)
+	require.True(t, errorx.IsOfType(err, errorx.ExternalError))
+	require.False(t, errorx.IsOfType(err, pkg.DecryptionFailed))
+}
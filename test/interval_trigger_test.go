@@ -0,0 +1,45 @@
+package test
+
+import (
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestIntervalTriggerFixedRate(t *testing.T) {
+	anchor := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	trigger, err := pkg.NewIntervalTrigger(anchor, 90*time.Second, pkg.IntervalModeFixedRate)
+	require.NoError(t, err)
+	// before the anchor fires at the anchor
+	require.Equal(t, anchor, *trigger.GetFireTime(anchor.Add(-time.Hour)))
+	// fire times stay on the anchored schedule
+	require.Equal(t, anchor.Add(90*time.Second), *trigger.GetFireTime(anchor))
+	require.Equal(t, anchor.Add(180*time.Second), *trigger.GetFireTime(anchor.Add(100 * time.Second)))
+	require.Equal(t, anchor.Add(270*time.Second), *trigger.GetFireTime(anchor.Add(180 * time.Second)))
+	require.False(t, trigger.FiresAfterCompletion())
+}
+
+func TestIntervalTriggerFixedDelay(t *testing.T) {
+	anchor := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	trigger, err := pkg.NewIntervalTrigger(anchor, 36*time.Hour, pkg.IntervalModeFixedDelay)
+	require.NoError(t, err)
+	require.Equal(t, anchor, *trigger.GetFireTime(anchor.Add(-time.Hour)))
+	// fire times are measured from the given time, which is the previous completion
+	completedAt := anchor.Add(17 * time.Minute)
+	require.Equal(t, completedAt.Add(36*time.Hour), *trigger.GetFireTime(completedAt))
+	require.True(t, trigger.FiresAfterCompletion())
+}
+
+func TestIntervalTriggerValidation(t *testing.T) {
+	_, err := pkg.NewIntervalTrigger(time.Now(), 0, pkg.IntervalModeFixedRate)
+	require.Error(t, err)
+	_, err = pkg.NewIntervalTrigger(time.Now(), time.Second, "sometimes")
+	require.Error(t, err)
+	trigger, err := pkg.NewIntervalTrigger(time.Now(), time.Second, "")
+	require.NoError(t, err)
+	require.Equal(t, pkg.IntervalModeFixedRate, trigger.Mode)
+	// triggers built without NewIntervalTrigger() don't fire instead of panicking
+	require.Nil(t, pkg.IntervalTrigger{Anchor: time.Now().Add(-time.Hour)}.GetFireTime(time.Now()))
+	require.Nil(t, pkg.IntervalTrigger{Anchor: time.Now().Add(-time.Hour), Period: -time.Second}.GetFireTime(time.Now()))
+}
@@ -0,0 +1,17 @@
+package test
+
+import (
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestNamespaceConcurrencyMustBePositive(t *testing.T) {
+	handler := func(task pkg.TaskInstance) error { return nil }
+	for _, maxConcurrent := range []int{0, -1} {
+		// options are validated before the store is used
+		_, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, nil, pkg.WithNamespaceConcurrency("team", maxConcurrent))
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestNaturalLanguageTriggerPhrases(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	// a monday morning
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, chicago)
+	cases := []struct {
+		text       string
+		normalized string
+		fireTimes  []time.Time
+	}{
+		{"in 3 hours", "once at 2026-10-19T13:00:00-05:00", []time.Time{time.Date(2026, 10, 19, 13, 0, 0, 0, chicago)}},
+		{"tomorrow at noon", "once at 2026-10-20T12:00:00-05:00", []time.Time{time.Date(2026, 10, 20, 12, 0, 0, 0, chicago)}},
+		{"at 9am", "once at 2026-10-20T09:00:00-05:00", []time.Time{time.Date(2026, 10, 20, 9, 0, 0, 0, chicago)}},
+		{"on November 3 at 5:30 pm", "once at 2026-11-03T17:30:00-06:00", []time.Time{time.Date(2026, 11, 3, 17, 30, 0, 0, chicago)}},
+		{"every 90 minutes", "every 90 minutes starting 2026-10-19T10:00:00-05:00", []time.Time{
+			time.Date(2026, 10, 19, 11, 30, 0, 0, chicago),
+			time.Date(2026, 10, 19, 13, 0, 0, 0, chicago),
+		}},
+		{"every other Tuesday at 9:34am", "every 2 weeks on tuesday at 09:34 starting 2026-10-19", []time.Time{
+			time.Date(2026, 10, 20, 9, 34, 0, 0, chicago),
+			time.Date(2026, 11, 3, 9, 34, 0, 0, chicago),
+			time.Date(2026, 11, 17, 9, 34, 0, 0, chicago),
+		}},
+		{"every weekday at 17:00", "every 1 weekdays at 17:00 starting 2026-10-19", []time.Time{
+			time.Date(2026, 10, 19, 17, 0, 0, 0, chicago),
+			time.Date(2026, 10, 20, 17, 0, 0, 0, chicago),
+			time.Date(2026, 10, 21, 17, 0, 0, 0, chicago),
+			time.Date(2026, 10, 22, 17, 0, 0, 0, chicago),
+			time.Date(2026, 10, 23, 17, 0, 0, 0, chicago),
+			time.Date(2026, 10, 26, 17, 0, 0, 0, chicago),
+		}},
+		{"every other weekday at noon", "every 2 weekdays at 12:00 starting 2026-10-19", []time.Time{
+			time.Date(2026, 10, 19, 12, 0, 0, 0, chicago),
+			time.Date(2026, 10, 21, 12, 0, 0, 0, chicago),
+			time.Date(2026, 10, 23, 12, 0, 0, 0, chicago),
+			time.Date(2026, 10, 27, 12, 0, 0, 0, chicago),
+		}},
+		{"every monday and thursday", "every 1 weeks on monday and thursday at 00:00 starting 2026-10-19", []time.Time{
+			time.Date(2026, 10, 22, 0, 0, 0, 0, chicago),
+			time.Date(2026, 10, 26, 0, 0, 0, 0, chicago),
+		}},
+		{"the last Friday of the month at 3pm", "every 1 months on the last friday at 15:00 starting 2026-10-19", []time.Time{
+			time.Date(2026, 10, 30, 15, 0, 0, 0, chicago),
+			time.Date(2026, 11, 27, 15, 0, 0, 0, chicago),
+			time.Date(2026, 12, 25, 15, 0, 0, 0, chicago),
+		}},
+		{"the 31st of every month", "every 1 months on day 31 at 00:00 starting 2026-10-19", []time.Time{
+			time.Date(2026, 10, 31, 0, 0, 0, 0, chicago),
+			time.Date(2026, 12, 31, 0, 0, 0, 0, chicago),
+		}},
+		{"every month on the first monday at 08:00", "every 1 months on the first monday at 08:00 starting 2026-10-19", []time.Time{
+			time.Date(2026, 11, 2, 8, 0, 0, 0, chicago),
+			time.Date(2026, 12, 7, 8, 0, 0, 0, chicago),
+		}},
+	}
+	for _, c := range cases {
+		trigger, err := pkg.NewNaturalLanguageTrigger(c.text, now, chicago)
+		require.NoError(t, err, c.text)
+		require.Equal(t, c.text, trigger.Text)
+		require.Equal(t, c.normalized, trigger.Normalized, c.text)
+		require.Equal(t, len(c.fireTimes) > 1, trigger.IsRecurring(), c.text)
+		from := now
+		for _, expected := range c.fireTimes {
+			fireTime := trigger.GetFireTime(from)
+			require.NotNil(t, fireTime, c.text)
+			require.True(t, expected.Equal(*fireTime), "%s: expected %s, got %s", c.text, expected, fireTime)
+			from = *fireTime
+		}
+		// the normalized form parses to the same schedule no matter when it's parsed
+		reparsed, err := pkg.NewNaturalLanguageTrigger(trigger.Normalized, now.AddDate(0, 0, 3), chicago)
+		require.NoError(t, err, c.normalized)
+		require.Equal(t, c.normalized, reparsed.Normalized)
+		// and so does a trigger that's been through json, like one loaded from the store
+		loaded := pkg.NaturalLanguageTrigger{Text: trigger.Text, Normalized: trigger.Normalized, Location: trigger.Location}
+		require.True(t, trigger.GetFireTime(now).Equal(*loaded.GetFireTime(now)), c.text)
+	}
+}
+
+func TestNaturalLanguageTriggerRejectsAmbiguousText(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	for _, text := range []string{
+		"",
+		"every tuesday at 9",
+		"tomorrow at 9:30",
+		"on 03/04",
+		"next tuesday",
+		"every month",
+		"every week",
+		"weekly",
+		"today at 8am",
+		"on the 15th",
+		"every fortnight",
+		"the 30th of february",
+		"every 0 minutes",
+		"every 9007199254740992 minutes",
+		"every 1000000000 minutes",
+		"every 100000 hours",
+		"every 1000 days",
+		"every 500 weekdays",
+		"every 100 weeks on monday",
+		"every 13 months on the 1st",
+		"the 1st of every 100 months",
+	} {
+		_, err := pkg.NewNaturalLanguageTrigger(text, now, nil)
+		require.Error(t, err, text)
+	}
+	_, err := pkg.NewNaturalLanguageTrigger("every tuesday at 9", now, nil)
+	require.Contains(t, err.Error(), "9am or 9pm")
+}
+
+func TestNaturalLanguageTriggerDefaultsToUTC(t *testing.T) {
+	now := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	trigger, err := pkg.NewNaturalLanguageTrigger("every day at 09:00", now, nil)
+	require.NoError(t, err)
+	require.Equal(t, "UTC", trigger.Location)
+	require.Equal(t, time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC), trigger.GetFireTime(now).UTC())
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRRuleTriggerRecurrence(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	// every other tuesday at 9:34am for 4 occurrences, except the second, plus a one off saturday
+	trigger, err := pkg.NewRRuleTrigger(`DTSTART;TZID=America/Chicago:20261020T093400
+RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;COUNT=4
+RDATE;TZID=America/Chicago:20261107T120000
+EXDATE;TZID=America/Chicago:20261103T093400`)
+	require.NoError(t, err)
+	require.True(t, trigger.IsRecurring())
+	expected := []time.Time{
+		time.Date(2026, 10, 20, 9, 34, 0, 0, chicago),
+		// daylight saving ends on 2026-11-01, the wall clock time stays the same
+		time.Date(2026, 11, 7, 12, 0, 0, 0, chicago),
+		time.Date(2026, 11, 17, 9, 34, 0, 0, chicago),
+		time.Date(2026, 12, 1, 9, 34, 0, 0, chicago),
+	}
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for _, expectedFireTime := range expected {
+		fireTime := trigger.GetFireTime(from)
+		require.NotNil(t, fireTime)
+		require.True(t, expectedFireTime.Equal(*fireTime), "expected %s, got %s", expectedFireTime, fireTime)
+		from = *fireTime
+	}
+	// exhausted by COUNT
+	require.Nil(t, trigger.GetFireTime(from))
+	// a trigger that's been through json, like one loaded from the store, evaluates the same
+	loaded := pkg.RRuleTrigger{Recurrence: trigger.Recurrence}
+	require.True(t, expected[0].Equal(*loaded.GetFireTime(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))))
+}
+
+func TestRRuleTriggerBySetPos(t *testing.T) {
+	// the last weekday of the month, until the end of the year
+	trigger, err := pkg.NewRRuleTrigger("DTSTART:20261001T170000Z\nRRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;UNTIL=20261231T235959Z")
+	require.NoError(t, err)
+	fireTime := trigger.GetFireTime(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	require.Equal(t, time.Date(2026, 10, 30, 17, 0, 0, 0, time.UTC), fireTime.UTC())
+	fireTime = trigger.GetFireTime(*fireTime)
+	require.Equal(t, time.Date(2026, 11, 30, 17, 0, 0, 0, time.UTC), fireTime.UTC())
+	fireTime = trigger.GetFireTime(*fireTime)
+	require.Equal(t, time.Date(2026, 12, 31, 17, 0, 0, 0, time.UTC), fireTime.UTC())
+	require.Nil(t, trigger.GetFireTime(*fireTime))
+}
+
+func TestRRuleTriggerLocation(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	// times without a TZID are in the trigger's location
+	trigger, err := pkg.NewRRuleTriggerWithLocation("DTSTART:20261020T090000\nRRULE:FREQ=DAILY", chicago)
+	require.NoError(t, err)
+	require.Equal(t, "America/Chicago", trigger.Location)
+	fireTime := trigger.GetFireTime(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC))
+	require.Equal(t, time.Date(2026, 10, 20, 14, 0, 0, 0, time.UTC), fireTime.UTC())
+}
+
+func TestRRuleTriggerValidation(t *testing.T) {
+	for _, recurrence := range []string{
+		"",
+		"RRULE:FREQ=DAILY",
+		"DTSTART:20261020T090000Z",
+		"DTSTART:20261020T090000Z\nRRULE:FREQ=SOMETIMES",
+		"DTSTART;TZID=Nowhere/Special:20261020T090000\nRRULE:FREQ=DAILY",
+	} {
+		_, err := pkg.NewRRuleTrigger(recurrence)
+		require.Error(t, err, recurrence)
+	}
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTaskDefinitionBounds(t *testing.T) {
+	trigger, err := pkg.NewCronTrigger("0 0 9 * * * *")
+	require.NoError(t, err)
+	startAt := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	endAt := time.Date(2026, 10, 22, 9, 0, 0, 0, time.UTC)
+	definition := pkg.TaskDefinition{CronTrigger: trigger, StartAt: &startAt, EndAt: &endAt}
+	// the start itself can fire
+	fireTime := definition.GetFireTimeFrom(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	require.Equal(t, startAt, *fireTime)
+	// and so can the end
+	fireTime = definition.GetFireTimeFrom(time.Date(2026, 10, 21, 12, 0, 0, 0, time.UTC))
+	require.Equal(t, endAt, *fireTime)
+	require.Nil(t, definition.GetFireTimeFrom(endAt))
+	// no more fire times once the max occurrences are reached
+	definition = pkg.TaskDefinition{CronTrigger: trigger, MaxOccurrences: 2, Occurrences: 1}
+	require.NotNil(t, definition.GetFireTimeFrom(startAt))
+	definition.Occurrences = 2
+	require.Nil(t, definition.GetFireTimeFrom(startAt))
+}
+
+func TestTaskDefinitionJitter(t *testing.T) {
+	trigger, err := pkg.NewCronTrigger("0 0 9 * * * *")
+	require.NoError(t, err)
+	from := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	offsets := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		id := uuid.New()
+		definition := pkg.TaskDefinition{Id: &id, CronTrigger: trigger, Jitter: 10 * time.Minute}
+		offset := definition.JitterOffset()
+		require.GreaterOrEqual(t, offset, time.Duration(0))
+		require.Less(t, offset, 10*time.Minute)
+		// the offset only depends on the id
+		sameId := id
+		require.Equal(t, offset, pkg.TaskDefinition{Id: &sameId, CronTrigger: trigger, Jitter: 10 * time.Minute}.JitterOffset())
+		offsets[offset] = true
+		// fire times are shifted by the offset and keep their spacing
+		fireTime := definition.GetFireTimeFrom(from)
+		require.Equal(t, time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC).Add(offset), *fireTime)
+		fireTime = definition.GetFireTimeFrom(*fireTime)
+		require.Equal(t, time.Date(2026, 10, 21, 9, 0, 0, 0, time.UTC).Add(offset), *fireTime)
+	}
+	require.Greater(t, len(offsets), 90)
+}
@@ -8,7 +8,9 @@ import (
 	"github.com/catalystsquad/app-utils-go/logging"
 	"github.com/catalystsquad/go-scheduler/pkg"
 	"github.com/google/uuid"
+	"github.com/joomcode/errorx"
 	"github.com/stretchr/testify/require"
+	"sync"
 	"testing"
 	"time"
 )
@@ -176,11 +178,11 @@ func TestGetTaskInstancesToRunNotInProgressNotExpired(t *testing.T, store pkg.St
 	require.Len(t, listedTaskInstances, 1)
 	listedTaskInstance := listedTaskInstances[0]
 	// query for tasks to run with a limit of now(), shouldn't come back
-	taskInstancesToRun, err := store.GetTaskInstancesToRun(time.Now())
+	taskInstancesToRun, err := store.GetTaskInstancesToRun(time.Now(), nil)
 	require.NoError(t, err)
 	require.Len(t, taskInstancesToRun, 0)
 	// query for tasks to run in the next 5 minutes, should get the task instance back
-	taskInstancesToRun, err = store.GetTaskInstancesToRun(time.Now().Add(5 * time.Minute))
+	taskInstancesToRun, err = store.GetTaskInstancesToRun(time.Now().Add(5*time.Minute), nil)
 	require.NoError(t, err)
 	require.Len(t, taskInstancesToRun, 1)
 	taskInstanceToRun := taskInstancesToRun[0]
@@ -209,11 +211,11 @@ func TestGetTaskInstancesToRunInProgressNotExpired(t *testing.T, store pkg.Store
 	require.NoError(t, err)
 	require.Len(t, listedTaskInstances, 1)
 	// query for tasks to run with a limit of now(), shouldn't come back
-	taskInstancesToRun, err := store.GetTaskInstancesToRun(time.Now())
+	taskInstancesToRun, err := store.GetTaskInstancesToRun(time.Now(), nil)
 	require.NoError(t, err)
 	require.Len(t, taskInstancesToRun, 0)
 	// query for tasks to run in the next 5 minutes, shouldn't get the task instance back because it's already in progress
-	taskInstancesToRun, err = store.GetTaskInstancesToRun(time.Now().Add(5 * time.Minute))
+	taskInstancesToRun, err = store.GetTaskInstancesToRun(time.Now().Add(5*time.Minute), nil)
 	require.NoError(t, err)
 	require.Len(t, taskInstancesToRun, 0)
 }
@@ -241,12 +243,12 @@ func TestGetTaskInstancesToRunInProgressAndExpired(t *testing.T, store pkg.Store
 	require.Len(t, listedTaskInstances, 1)
 	listedTaskInstance := listedTaskInstances[0]
 	// query for tasks to run with a limit of now(), shouldn't come back
-	taskInstancesToRun, err := store.GetTaskInstancesToRun(time.Now())
+	taskInstancesToRun, err := store.GetTaskInstancesToRun(time.Now(), nil)
 	require.NoError(t, err)
 	require.Len(t, taskInstancesToRun, 0)
 	// sleep until the expiration, then query for tasks to run in the next 5 minutes, should get the instance back because it's expired
 	time.Sleep(time.Until(*listedTaskInstance.ExpiresAt))
-	taskInstancesToRun, err = store.GetTaskInstancesToRun(time.Now().Add(5 * time.Minute))
+	taskInstancesToRun, err = store.GetTaskInstancesToRun(time.Now().Add(5*time.Minute), nil)
 	require.NoError(t, err)
 	require.Len(t, taskInstancesToRun, 1)
 	require.Equal(t, listedTaskInstance.Id, taskInstancesToRun[0].Id)
@@ -273,11 +275,11 @@ func TestGetTaskInstancesToRun(t *testing.T, store pkg.StoreInterface) {
 	require.Len(t, listedTaskInstances, 1)
 	listedTaskInstance := listedTaskInstances[0]
 	// query for tasks to run, shouldn't come back yet
-	taskInstancesToRun, err := store.GetTaskInstancesToRun(time.Now())
+	taskInstancesToRun, err := store.GetTaskInstancesToRun(time.Now(), nil)
 	require.NoError(t, err)
 	require.Len(t, taskInstancesToRun, 0)
 	// query for tasks to run in the next 5 minutes, should get the task instance
-	taskInstancesToRun, err = store.GetTaskInstancesToRun(time.Now().Add(5 * time.Minute))
+	taskInstancesToRun, err = store.GetTaskInstancesToRun(time.Now().Add(5*time.Minute), nil)
 	require.NoError(t, err)
 	require.Len(t, taskInstancesToRun, 1)
 	taskInstanceToRun := taskInstancesToRun[0]
@@ -351,6 +353,32 @@ func TestMarkCompleted(t *testing.T, store pkg.StoreInterface) {
 	fetchedCronTaskDefinition, err := store.GetTaskDefinition(listedCronTaskDefinition.Id)
 	require.NoError(t, err)
 	require.Nil(t, fetchedCronTaskDefinition.CompletedAt)
+
+	// rescheduling a non-recurring task definition as its task instance completes keeps it from completing, so cleanup
+	// can't delete it
+	rescheduledTaskDefinition := generateRandomTaskWithExecuteOnceTrigger(time.Time{}, 0)
+	err = store.UpsertTaskDefinition(rescheduledTaskDefinition)
+	require.NoError(t, err)
+	rescheduledTaskInstance := generateRandomTaskInstance(rescheduledTaskDefinition)
+	rescheduledTaskInstanceId := uuid.New()
+	rescheduledTaskInstance.Id = &rescheduledTaskInstanceId
+	err = store.UpsertTaskInstance(rescheduledTaskInstance)
+	require.NoError(t, err)
+	rescheduleAt := time.Now().Add(1 * time.Hour)
+	err = store.MarkTaskInstanceDoneAndReschedule(rescheduledTaskInstance, false, &rescheduleAt)
+	require.NoError(t, err)
+	fetchedRescheduledTaskInstance, err := store.GetTaskInstance(rescheduledTaskInstance.Id)
+	require.NoError(t, err)
+	require.NotNil(t, fetchedRescheduledTaskInstance.CompletedAt)
+	require.Nil(t, fetchedRescheduledTaskInstance.FailedAt)
+	err = store.DeleteCompletedTaskInstances()
+	require.NoError(t, err)
+	err = store.DeleteCompletedTaskDefinitions()
+	require.NoError(t, err)
+	fetchedRescheduledTaskDefinition, err := store.GetTaskDefinition(rescheduledTaskDefinition.Id)
+	require.NoError(t, err)
+	require.Nil(t, fetchedRescheduledTaskDefinition.CompletedAt)
+	require.Equal(t, rescheduleAt.Unix(), fetchedRescheduledTaskDefinition.NextFireTime.Unix())
 }
 
 func TestCleanup(t *testing.T, store pkg.StoreInterface) {
@@ -718,6 +746,1122 @@ func TestCronTriggerNoRetry(t *testing.T, store pkg.StoreInterface) {
 	require.LessOrEqual(t, executionCount, 4)
 }
 
+func TestLeaderElection(t *testing.T, store pkg.StoreInterface) {
+	handler := func(task pkg.TaskInstance) error {
+		return nil
+	}
+	leaseDuration := 3 * time.Second
+	scheduler1, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, store, pkg.WithLeaderElection(leaseDuration))
+	require.NoError(t, err)
+	scheduler2, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, store, pkg.WithLeaderElection(leaseDuration))
+	require.NoError(t, err)
+	go scheduler1.Run()
+	time.Sleep(1 * time.Second)
+	go scheduler2.Run()
+	defer scheduler2.Stop()
+	time.Sleep(2 * time.Second)
+	// the first scheduler started first so it should be the only leader
+	require.True(t, scheduler1.IsLeader())
+	require.False(t, scheduler2.IsLeader())
+	// stopping the leader releases the lease, the second scheduler should take over within a renewal interval
+	scheduler1.Stop()
+	time.Sleep(leaseDuration)
+	require.False(t, scheduler1.IsLeader())
+	require.True(t, scheduler2.IsLeader())
+}
+
+func TestNodeRegistry(t *testing.T, store pkg.StoreInterface) {
+	var handledBy *uuid.UUID
+	handler := func(task pkg.TaskInstance) error {
+		handledBy = task.NodeId
+		return nil
+	}
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, store, pkg.WithHeartbeatInterval(1*time.Second), pkg.WithNodeVersion("test"))
+	require.NoError(t, err)
+	err = scheduler.UpsertTaskDefinition(generateRandomTaskWithExecuteOnceTrigger(time.Now().Add(1*time.Second), 0))
+	require.NoError(t, err)
+	go scheduler.Run()
+	time.Sleep(4 * time.Second)
+	// the scheduler should be registered and alive
+	nodes, err := scheduler.ListNodes()
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+	require.Equal(t, scheduler.NodeId(), *nodes[0].Id)
+	require.Equal(t, "test", nodes[0].Version)
+	require.True(t, nodes[0].Alive)
+	// the task instance should have been claimed by the scheduler
+	require.NotNil(t, handledBy)
+	require.Equal(t, scheduler.NodeId(), *handledBy)
+	// stopping the scheduler deregisters it
+	scheduler.Stop()
+	time.Sleep(1 * time.Second)
+	nodes, err = scheduler.ListNodes()
+	require.NoError(t, err)
+	require.Len(t, nodes, 0)
+}
+
+func TestSharding(t *testing.T, store pkg.StoreInterface) {
+	lock := new(sync.Mutex)
+	executions := map[uuid.UUID]int{}
+	handler := func(task pkg.TaskInstance) error {
+		lock.Lock()
+		defer lock.Unlock()
+		executions[*task.TaskDefinition.Id]++
+		return nil
+	}
+	scheduler1, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, store, pkg.WithSharding(), pkg.WithHeartbeatInterval(1*time.Second))
+	require.NoError(t, err)
+	scheduler2, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, store, pkg.WithSharding(), pkg.WithHeartbeatInterval(1*time.Second))
+	require.NoError(t, err)
+	go scheduler1.Run()
+	defer scheduler1.Stop()
+	go scheduler2.Run()
+	defer scheduler2.Stop()
+	// give both nodes a heartbeat to see each other before creating tasks
+	time.Sleep(2 * time.Second)
+	taskCount := 20
+	for i := 0; i < taskCount; i++ {
+		err = scheduler1.UpsertTaskDefinition(generateRandomTaskWithExecuteOnceTrigger(time.Now().Add(3*time.Second), 0))
+		require.NoError(t, err)
+	}
+	time.Sleep(6 * time.Second)
+	// every task should run exactly once, no matter which node owns its shard
+	require.Len(t, executions, taskCount)
+	for _, count := range executions {
+		require.Equal(t, 1, count)
+	}
+}
+
+func TestImmediatePickup(t *testing.T, store pkg.StoreInterface) {
+	executionCount := 0
+	handler := func(task pkg.TaskInstance) error {
+		executionCount++
+		return nil
+	}
+	// windows much longer than the task's delay, the task should fire without waiting for a tick
+	scheduler, err := pkg.NewScheduler(10*time.Second, 10*time.Second, 10*time.Second, handler, store)
+	require.NoError(t, err)
+	go scheduler.Run()
+	defer scheduler.Stop()
+	time.Sleep(100 * time.Millisecond)
+	err = scheduler.UpsertTaskDefinition(generateRandomTaskWithExecuteOnceTrigger(time.Now().Add(200*time.Millisecond), 0))
+	require.NoError(t, err)
+	time.Sleep(1 * time.Second)
+	require.Equal(t, 1, executionCount)
+	// the next ticks shouldn't run it again
+	time.Sleep(10 * time.Second)
+	require.Equal(t, 1, executionCount)
+}
+
+func TestMisfirePolicies(t *testing.T, store pkg.StoreInterface) {
+	policyExecutions := map[pkg.MisfirePolicy]int{}
+	lock := new(sync.Mutex)
+	handler := func(task pkg.TaskInstance) error {
+		lock.Lock()
+		defer lock.Unlock()
+		policyExecutions[task.TaskDefinition.MisfirePolicy]++
+		return nil
+	}
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, store, pkg.WithMaxMisfires(5))
+	require.NoError(t, err)
+	// hourly definitions whose next fire time was 10 hours ago, as if the scheduler was down
+	missedFireTime := time.Now().Add(-10 * time.Hour).Truncate(time.Hour).UTC()
+	for _, policy := range []pkg.MisfirePolicy{pkg.MisfirePolicySkip, pkg.MisfirePolicyFireOnce, pkg.MisfirePolicyFireAll} {
+		definition, err := generateRandomTaskWithCronTrigger("@hourly", 0)
+		require.NoError(t, err)
+		definition.MisfirePolicy = policy
+		definition.NextFireTime = &missedFireTime
+		err = store.UpsertTaskDefinition(definition)
+		require.NoError(t, err)
+	}
+	go scheduler.Run()
+	defer scheduler.Stop()
+	time.Sleep(4 * time.Second)
+	require.Equal(t, 0, policyExecutions[pkg.MisfirePolicySkip])
+	require.Equal(t, 1, policyExecutions[pkg.MisfirePolicyFireOnce])
+	// capped at the max misfires
+	require.Equal(t, 5, policyExecutions[pkg.MisfirePolicyFireAll])
+	// every definition should be skipped ahead to its next fire time
+	definitions, err := store.ListTaskDefinitions(0, 100, nil)
+	require.NoError(t, err)
+	require.Len(t, definitions, 3)
+	for _, definition := range definitions {
+		require.True(t, definition.NextFireTime.After(time.Now()))
+	}
+}
+
+func TestBackfill(t *testing.T, store pkg.StoreInterface) {
+	backfilledFireTimes := []time.Time{}
+	lock := new(sync.Mutex)
+	handler := func(task pkg.TaskInstance) error {
+		lock.Lock()
+		defer lock.Unlock()
+		if task.Backfill {
+			backfilledFireTimes = append(backfilledFireTimes, *task.FireTime)
+		}
+		return nil
+	}
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, store)
+	require.NoError(t, err)
+	// once per minute, at the top of the minute
+	definition, err := generateRandomTaskWithCronTrigger("0 * * * * * *", 0)
+	require.NoError(t, err)
+	err = scheduler.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	to := time.Now().Truncate(time.Minute)
+	from := to.Add(-9 * time.Minute)
+	taskInstances, err := scheduler.Backfill(definition.Id, from, to, pkg.BackfillOptions{Interval: 100 * time.Millisecond})
+	require.NoError(t, err)
+	// from and to are both included
+	require.Len(t, taskInstances, 10)
+	// backfilling again shouldn't create duplicates
+	taskInstances, err = scheduler.Backfill(definition.Id, from, to, pkg.BackfillOptions{})
+	require.NoError(t, err)
+	require.Len(t, taskInstances, 0)
+	go scheduler.Run()
+	defer scheduler.Stop()
+	time.Sleep(4 * time.Second)
+	require.Len(t, backfilledFireTimes, 10)
+	// executed in fire time order since they're spaced out
+	for i, fireTime := range backfilledFireTimes {
+		require.True(t, fireTime.Equal(from.Add(time.Duration(i)*time.Minute)))
+	}
+}
+
+func TestIntervalTriggerModes(t *testing.T, store pkg.StoreInterface) {
+	modeExecutions := map[pkg.IntervalMode]int{}
+	lock := new(sync.Mutex)
+	handler := func(task pkg.TaskInstance) error {
+		lock.Lock()
+		modeExecutions[task.TaskDefinition.IntervalTrigger.Mode]++
+		lock.Unlock()
+		// take as long as the period
+		time.Sleep(1 * time.Second)
+		return nil
+	}
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, store)
+	require.NoError(t, err)
+	// interval triggers built without NewIntervalTrigger() are validated too
+	invalid := generateRandomTaskWithoutTrigger()
+	invalid.IntervalTrigger = &pkg.IntervalTrigger{Anchor: time.Now()}
+	require.Error(t, scheduler.UpsertTaskDefinition(invalid))
+	invalid.IntervalTrigger = &pkg.IntervalTrigger{Anchor: time.Now(), Period: time.Second, Mode: "sometimes"}
+	require.Error(t, scheduler.UpsertTaskDefinition(invalid))
+	for _, mode := range []pkg.IntervalMode{pkg.IntervalModeFixedRate, pkg.IntervalModeFixedDelay} {
+		definition := generateRandomTaskWithoutTrigger()
+		definition.ExpireAfter = 1 * time.Minute
+		definition.IntervalTrigger, err = pkg.NewIntervalTrigger(time.Now().Add(1*time.Second), 1*time.Second, mode)
+		require.NoError(t, err)
+		err = scheduler.UpsertTaskDefinition(definition)
+		require.NoError(t, err)
+	}
+	go scheduler.Run()
+	defer scheduler.Stop()
+	time.Sleep(8500 * time.Millisecond)
+	// fixed rate fires every second regardless of the handler, fixed delay waits a second after each completion
+	require.GreaterOrEqual(t, modeExecutions[pkg.IntervalModeFixedRate], 7)
+	require.LessOrEqual(t, modeExecutions[pkg.IntervalModeFixedDelay], 5)
+	require.GreaterOrEqual(t, modeExecutions[pkg.IntervalModeFixedDelay], 3)
+}
+
+func TestNaturalLanguageTriggerPersistence(t *testing.T, store pkg.StoreInterface) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	definition := generateRandomTaskWithoutTrigger()
+	definition.ExpireAfter = 1 * time.Minute
+	definition.NaturalLanguageTrigger, err = pkg.NewNaturalLanguageTrigger("every other tuesday at 9:34am", time.Now(), chicago)
+	require.NoError(t, err)
+	definition.NextFireTime = definition.GetNextFireTime()
+	definition.Recurring = true
+	err = store.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	fetched, err := store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.NotNil(t, fetched.NaturalLanguageTrigger)
+	require.Equal(t, definition.NaturalLanguageTrigger.Text, fetched.NaturalLanguageTrigger.Text)
+	require.Equal(t, definition.NaturalLanguageTrigger.Normalized, fetched.NaturalLanguageTrigger.Normalized)
+	require.Equal(t, "America/Chicago", fetched.NaturalLanguageTrigger.Location)
+	require.True(t, definition.GetNextFireTime().Equal(*fetched.GetNextFireTime()))
+}
+
+func TestRRuleTriggerPersistence(t *testing.T, store pkg.StoreInterface) {
+	definition := generateRandomTaskWithoutTrigger()
+	definition.ExpireAfter = 1 * time.Minute
+	var err error
+	definition.RRuleTrigger, err = pkg.NewRRuleTrigger("DTSTART;TZID=America/Chicago:20261020T093400\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=TU\nEXDATE;TZID=America/Chicago:20261103T093400")
+	require.NoError(t, err)
+	definition.NextFireTime = definition.GetNextFireTime()
+	definition.Recurring = true
+	err = store.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	fetched, err := store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.NotNil(t, fetched.RRuleTrigger)
+	require.Equal(t, definition.RRuleTrigger.Recurrence, fetched.RRuleTrigger.Recurrence)
+	require.True(t, definition.GetNextFireTime().Equal(*fetched.GetNextFireTime()))
+}
+
+func TestTriggerBounds(t *testing.T, store pkg.StoreInterface) {
+	executions := map[uuid.UUID]int{}
+	lock := new(sync.Mutex)
+	handler := func(task pkg.TaskInstance) error {
+		lock.Lock()
+		executions[*task.TaskDefinition.Id]++
+		lock.Unlock()
+		return nil
+	}
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, store)
+	require.NoError(t, err)
+	// runs 3 times
+	limited, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 1*time.Minute)
+	require.NoError(t, err)
+	limited.MaxOccurrences = 3
+	// runs for 3 seconds, starting in 2
+	startAt := time.Now().Add(2 * time.Second).Truncate(time.Second)
+	endAt := startAt.Add(2 * time.Second)
+	bounded, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 1*time.Minute)
+	require.NoError(t, err)
+	bounded.StartAt = &startAt
+	bounded.EndAt = &endAt
+	for _, definition := range []pkg.TaskDefinition{limited, bounded} {
+		err = scheduler.UpsertTaskDefinition(definition)
+		require.NoError(t, err)
+	}
+	// updating a task definition keeps its occurrences, and one that's used them up is complete
+	exhausted, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 1*time.Minute)
+	require.NoError(t, err)
+	exhausted.MaxOccurrences = 5
+	err = scheduler.UpsertTaskDefinition(exhausted)
+	require.NoError(t, err)
+	stored, err := store.GetTaskDefinition(exhausted.Id)
+	require.NoError(t, err)
+	stored.Occurrences = 2
+	err = store.UpsertTaskDefinition(stored)
+	require.NoError(t, err)
+	exhausted.MaxOccurrences = 2
+	err = scheduler.UpsertTaskDefinition(exhausted)
+	require.NoError(t, err)
+	stored, err = store.GetTaskDefinition(exhausted.Id)
+	require.NoError(t, err)
+	require.Equal(t, 2, stored.Occurrences)
+	require.Nil(t, stored.NextFireTime)
+	require.NotNil(t, stored.CompletedAt)
+	// as is one whose end has passed
+	endedAt := time.Now().Add(-time.Minute)
+	ended, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 1*time.Minute)
+	require.NoError(t, err)
+	ended.EndAt = &endedAt
+	err = scheduler.UpsertTaskDefinition(ended)
+	require.NoError(t, err)
+	stored, err = store.GetTaskDefinition(ended.Id)
+	require.NoError(t, err)
+	require.NotNil(t, stored.CompletedAt)
+	go scheduler.Run()
+	defer scheduler.Stop()
+	time.Sleep(8500 * time.Millisecond)
+	lock.Lock()
+	require.Equal(t, 3, executions[*limited.Id])
+	require.Equal(t, 3, executions[*bounded.Id])
+	lock.Unlock()
+	// exhausted definitions are completed and cleaned up
+	for _, definition := range []pkg.TaskDefinition{limited, bounded, exhausted, ended} {
+		_, err = store.GetTaskDefinition(definition.Id)
+		require.Error(t, err)
+	}
+}
+
+func TestCalendars(t *testing.T, store pkg.StoreInterface) {
+	var firstExecution time.Time
+	lock := new(sync.Mutex)
+	handler := func(task pkg.TaskInstance) error {
+		lock.Lock()
+		if firstExecution.IsZero() {
+			firstExecution = time.Now()
+		}
+		lock.Unlock()
+		return nil
+	}
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, store)
+	require.NoError(t, err)
+	// calendar crud
+	blackoutEnd := time.Now().Add(4 * time.Second)
+	calendar := pkg.Calendar{Name: gofakeit.UUID(), Ranges: []pkg.TimeRange{{Start: time.Now().Add(-time.Hour), End: blackoutEnd}}}
+	err = scheduler.UpsertCalendar(calendar)
+	require.NoError(t, err)
+	fetched, err := scheduler.GetCalendar(calendar.Name)
+	require.NoError(t, err)
+	require.Len(t, fetched.Ranges, 1)
+	require.True(t, blackoutEnd.Equal(fetched.Ranges[0].End))
+	calendars, err := scheduler.ListCalendars()
+	require.NoError(t, err)
+	require.NotEmpty(t, calendars)
+	// task definitions can't reference calendars that don't exist
+	definition, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 1*time.Minute)
+	require.NoError(t, err)
+	definition.CalendarName = gofakeit.UUID()
+	require.Error(t, scheduler.UpsertTaskDefinition(definition))
+	// fire times during the blackout are skipped
+	definition.CalendarName = calendar.Name
+	err = scheduler.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	go scheduler.Run()
+	defer scheduler.Stop()
+	time.Sleep(7 * time.Second)
+	lock.Lock()
+	require.False(t, firstExecution.IsZero())
+	require.False(t, firstExecution.Before(blackoutEnd))
+	lock.Unlock()
+	err = scheduler.DeleteCalendar(calendar.Name)
+	require.NoError(t, err)
+	_, err = scheduler.GetCalendar(calendar.Name)
+	require.Error(t, err)
+}
+
+func TestJitter(t *testing.T, store pkg.StoreInterface) {
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, func(task pkg.TaskInstance) error { return nil }, store)
+	require.NoError(t, err)
+	// daily at 9am, spread over an hour
+	definition, err := generateRandomTaskWithCronTrigger("0 0 9 * * * *", 1*time.Minute)
+	require.NoError(t, err)
+	// offsets are whole milliseconds, a smaller jitter would never shift anything
+	definition.Jitter = time.Microsecond
+	require.Error(t, scheduler.UpsertTaskDefinition(definition))
+	definition.Jitter = 1 * time.Hour
+	err = scheduler.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	fetched, err := store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.Equal(t, definition.Jitter, fetched.Jitter)
+	unjittered := definition
+	unjittered.Jitter = 0
+	expected := unjittered.GetFireTimeFrom(time.Now().Add(-definition.JitterOffset())).Add(definition.JitterOffset())
+	require.True(t, expected.Equal(*fetched.NextFireTime), "expected %s, got %s", expected, fetched.NextFireTime)
+}
+
+func TestRegisteredTriggerPersistence(t *testing.T, store pkg.StoreInterface) {
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, func(task pkg.TaskInstance) error { return nil }, store)
+	require.NoError(t, err)
+	definition := generateRandomTaskWithoutTrigger()
+	definition.Trigger, err = pkg.NewRegisteredTrigger(everyOtherSecondTrigger{Offset: 1})
+	require.NoError(t, err)
+	err = scheduler.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	fetched, err := store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.Equal(t, everyOtherSecondTrigger{Offset: 1}, fetched.GetTrigger())
+	require.True(t, fetched.Recurring)
+}
+
+func TestTriggerNow(t *testing.T, store pkg.StoreInterface) {
+	manualTaskInstances := []pkg.TaskInstance{}
+	lock := new(sync.Mutex)
+	handler := func(task pkg.TaskInstance) error {
+		lock.Lock()
+		defer lock.Unlock()
+		if task.Manual {
+			manualTaskInstances = append(manualTaskInstances, task)
+		}
+		return nil
+	}
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, store)
+	require.NoError(t, err)
+	fireAt := time.Now().Add(1 * time.Hour).UTC().Truncate(time.Microsecond)
+	definition := GenerateExecuteOnceTask(&fireAt)
+	definition.Metadata = TestMetaData{Message: "scheduled"}
+	err = scheduler.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	go scheduler.Run()
+	defer scheduler.Stop()
+	time.Sleep(1 * time.Second)
+	_, err = scheduler.TriggerNow(definition.Id, pkg.TriggerNowOptions{Metadata: TestMetaData{Message: "manual"}})
+	require.NoError(t, err)
+	time.Sleep(2 * time.Second)
+	lock.Lock()
+	require.Len(t, manualTaskInstances, 1)
+	// the runner may pick the task instance up from the store first, where the metadata is a map
+	metadata, err := json.Marshal(manualTaskInstances[0].GetMetadata())
+	require.NoError(t, err)
+	require.Contains(t, string(metadata), "manual")
+	lock.Unlock()
+	// the definition still fires on its schedule
+	fetched, err := store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.Nil(t, fetched.CompletedAt)
+	require.True(t, fetched.NextFireTime.Equal(fireAt))
+}
+
+func TestWorkflows(t *testing.T, store pkg.StoreInterface) {
+	handled := map[uuid.UUID]int{}
+	lock := new(sync.Mutex)
+	var failing *uuid.UUID
+	handler := func(task pkg.TaskInstance) error {
+		lock.Lock()
+		defer lock.Unlock()
+		handled[*task.TaskDefinition.Id]++
+		if *task.TaskDefinition.Id == *failing {
+			return errors.New("transform failed")
+		}
+		return nil
+	}
+	// clean up less often than the test runs, so the workflow run's task instances are still there afterwards
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Minute, handler, store)
+	require.NoError(t, err)
+	fireAt := time.Now().Add(2 * time.Second)
+	export := GenerateExecuteOnceTask(&fireAt)
+	export.Metadata = TestMetaData{Message: "export"}
+	err = scheduler.UpsertTaskDefinition(export)
+	require.NoError(t, err)
+	transform := generateRandomTaskWithoutTrigger()
+	transform.DependsOn = []*uuid.UUID{export.Id}
+	transform.MaxAttempts = 1
+	failing = transform.Id
+	err = scheduler.UpsertTaskDefinition(transform)
+	require.NoError(t, err)
+	// skipped because transform fails
+	notify := generateRandomTaskWithoutTrigger()
+	notify.DependsOn = []*uuid.UUID{transform.Id}
+	err = scheduler.UpsertTaskDefinition(notify)
+	require.NoError(t, err)
+	// runs anyway
+	cleanup := generateRandomTaskWithoutTrigger()
+	cleanup.DependsOn = []*uuid.UUID{transform.Id}
+	cleanup.FailurePolicy = pkg.FailurePolicyContinue
+	err = scheduler.UpsertTaskDefinition(cleanup)
+	require.NoError(t, err)
+	// cycles are rejected
+	export.DependsOn = []*uuid.UUID{notify.Id}
+	export.ExecuteOnceTrigger = nil
+	err = scheduler.UpsertTaskDefinition(export)
+	require.Error(t, err)
+	go scheduler.Run()
+	defer scheduler.Stop()
+	time.Sleep(6 * time.Second)
+	lock.Lock()
+	require.Equal(t, 1, handled[*export.Id])
+	require.Equal(t, 1, handled[*transform.Id])
+	require.Equal(t, 0, handled[*notify.Id])
+	require.Equal(t, 1, handled[*cleanup.Id])
+	lock.Unlock()
+	runs, err := scheduler.ListWorkflowRuns(export.Id, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, pkg.WorkflowRunStatusFailed, runs[0].Status)
+	require.NotNil(t, runs[0].CompletedAt)
+	taskInstances, err := scheduler.ListWorkflowRunTaskInstances(runs[0].Id)
+	require.NoError(t, err)
+	require.Len(t, taskInstances, 4)
+	for _, taskInstance := range taskInstances {
+		require.NotNil(t, taskInstance.CompletedAt)
+		require.Equal(t, *taskInstance.TaskDefinition.Id == *notify.Id, taskInstance.Skipped)
+		require.Equal(t, *taskInstance.TaskDefinition.Id == *transform.Id, taskInstance.FailedAt != nil)
+	}
+}
+
+func TestHandlerDirectives(t *testing.T, store pkg.StoreInterface) {
+	messages := map[uuid.UUID]string{}
+	executions := map[string]int{}
+	lock := new(sync.Mutex)
+	handler := func(task pkg.TaskInstance) error {
+		lock.Lock()
+		defer lock.Unlock()
+		message := messages[*task.TaskDefinition.Id]
+		executions[message]++
+		if executions[message] > 1 {
+			return nil
+		}
+		switch message {
+		case "snooze":
+			return pkg.SnoozeUntil(time.Now().Add(1 * time.Second))
+		case "retry":
+			return fmt.Errorf("user is offline: %w", pkg.RetryAfter(1*time.Second))
+		case "fail", "fail fixed delay":
+			return pkg.PermanentFailure(errors.New("this will never succeed"))
+		case "reschedule":
+			return pkg.RescheduleAt(time.Now().Add(1 * time.Second))
+		}
+		return nil
+	}
+	// clean up less often than the test runs, so the failed task instance is still there afterwards
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Minute, handler, store)
+	require.NoError(t, err)
+	definitions := map[string]pkg.TaskDefinition{}
+	for _, message := range []string{"snooze", "retry", "fail", "reschedule"} {
+		fireAt := time.Now().Add(1 * time.Second)
+		definition := GenerateExecuteOnceTask(&fireAt)
+		definition.Metadata = TestMetaData{Message: message}
+		definition.ExpireAfter = 1 * time.Minute
+		lock.Lock()
+		messages[*definition.Id] = message
+		lock.Unlock()
+		err = scheduler.UpsertTaskDefinition(definition)
+		require.NoError(t, err)
+		definitions[message] = definition
+	}
+	// triggers that fire after completion keep firing after a permanent failure
+	fixedDelay := generateRandomTaskWithoutTrigger()
+	fixedDelay.Metadata = TestMetaData{Message: "fail fixed delay"}
+	fixedDelay.ExpireAfter = 1 * time.Minute
+	fixedDelay.IntervalTrigger, err = pkg.NewIntervalTrigger(time.Now().Add(1*time.Second), 1*time.Second, pkg.IntervalModeFixedDelay)
+	require.NoError(t, err)
+	lock.Lock()
+	messages[*fixedDelay.Id] = "fail fixed delay"
+	lock.Unlock()
+	err = scheduler.UpsertTaskDefinition(fixedDelay)
+	require.NoError(t, err)
+	go scheduler.Run()
+	defer scheduler.Stop()
+	time.Sleep(6 * time.Second)
+	lock.Lock()
+	// snoozed, retried and rescheduled tasks run again well before they'd expire
+	require.Equal(t, 2, executions["snooze"])
+	require.Equal(t, 2, executions["retry"])
+	require.Equal(t, 1, executions["fail"])
+	require.Equal(t, 2, executions["reschedule"])
+	require.GreaterOrEqual(t, executions["fail fixed delay"], 2)
+	lock.Unlock()
+	failed := definitions["fail"]
+	taskInstances, err := store.ListTaskInstancesForTaskDefinition(failed.Id, time.Now().Add(-1*time.Minute), time.Now())
+	require.NoError(t, err)
+	require.Len(t, taskInstances, 1)
+	require.NotNil(t, taskInstances[0].FailedAt)
+}
+
+func TestWindowTriggerPlacement(t *testing.T, store pkg.StoreInterface) {
+	// clean up less often than the test runs, so the task instances are still there afterwards
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Minute, func(task pkg.TaskInstance) error { return nil }, store)
+	require.NoError(t, err)
+	// window triggers built without NewWindowTrigger() are validated too
+	invalid := generateRandomTaskWithoutTrigger()
+	invalid.WindowTrigger = &pkg.WindowTrigger{Expression: "*/10 * * * * * *", Duration: 24 * time.Hour, Slot: time.Nanosecond}
+	require.Error(t, scheduler.UpsertTaskDefinition(invalid))
+	definitions := []pkg.TaskDefinition{}
+	for i := 0; i < 3; i++ {
+		definition := generateRandomTaskWithoutTrigger()
+		// windows open every 10 seconds and last 3 seconds, with a slot per second
+		definition.WindowTrigger, err = pkg.NewWindowTrigger("*/10 * * * * * *", 3*time.Second, 1*time.Second, nil)
+		require.NoError(t, err)
+		err = scheduler.UpsertTaskDefinition(definition)
+		require.NoError(t, err)
+		definitions = append(definitions, definition)
+	}
+	go scheduler.Run()
+	defer scheduler.Stop()
+	time.Sleep(12 * time.Second)
+	executeAts := map[time.Time]bool{}
+	for _, definition := range definitions {
+		taskInstances, err := store.ListTaskInstancesForTaskDefinition(definition.Id, time.Now().Add(-1*time.Minute), time.Now())
+		require.NoError(t, err)
+		require.NotEmpty(t, taskInstances)
+		taskInstance := taskInstances[0]
+		// placed within the window, at the start of a slot
+		require.False(t, taskInstance.ExecuteAt.Before(*taskInstance.FireTime))
+		require.True(t, taskInstance.ExecuteAt.Before(taskInstance.FireTime.Add(3*time.Second)))
+		require.Zero(t, taskInstance.ExecuteAt.Sub(*taskInstance.FireTime)%time.Second)
+		executeAts[taskInstance.ExecuteAt.UTC()] = true
+	}
+	// spread out over the window's slots instead of all at its start
+	require.Greater(t, len(executeAts), 1)
+}
+
+func TestPauseResume(t *testing.T, store pkg.StoreInterface) {
+	executions := 0
+	lock := new(sync.Mutex)
+	handler := func(task pkg.TaskInstance) error {
+		lock.Lock()
+		defer lock.Unlock()
+		executions++
+		return nil
+	}
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, store)
+	require.NoError(t, err)
+	definition, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 0)
+	require.NoError(t, err)
+	err = scheduler.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	go scheduler.Run()
+	defer scheduler.Stop()
+	time.Sleep(3 * time.Second)
+	err = scheduler.PauseTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	// upserting doesn't unpause
+	err = scheduler.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	fetched, err := store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.True(t, fetched.Paused)
+	require.NotNil(t, fetched.PausedAt)
+	// task instances already picked up still run
+	time.Sleep(3 * time.Second)
+	lock.Lock()
+	pausedExecutions := executions
+	lock.Unlock()
+	time.Sleep(3 * time.Second)
+	lock.Lock()
+	require.Equal(t, pausedExecutions, executions)
+	lock.Unlock()
+	err = scheduler.ResumeTaskDefinition(definition.Id, pkg.ResumeSkipMissed)
+	require.NoError(t, err)
+	fetched, err = store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.False(t, fetched.Paused)
+	// missed fire times are skipped
+	require.False(t, fetched.NextFireTime.Before(time.Now().Add(-1*time.Second)))
+	time.Sleep(3 * time.Second)
+	lock.Lock()
+	require.Greater(t, executions, pausedExecutions)
+	// at most one per second since resuming, the missed ones didn't run
+	require.LessOrEqual(t, executions, pausedExecutions+4)
+	lock.Unlock()
+}
+
+func TestNamespaces(t *testing.T, store pkg.StoreInterface) {
+	namespace := fmt.Sprintf("team-%s", uuid.New())
+	otherNamespace := fmt.Sprintf("team-%s", uuid.New())
+	lock := new(sync.Mutex)
+	running := 0
+	maxRunning := 0
+	namespaceExecutions := 0
+	namespaceHandler := func(task pkg.TaskInstance) error {
+		lock.Lock()
+		namespaceExecutions++
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+		time.Sleep(200 * time.Millisecond)
+		lock.Lock()
+		running--
+		lock.Unlock()
+		return nil
+	}
+	otherExecutions := 0
+	handler := func(task pkg.TaskInstance) error {
+		lock.Lock()
+		defer lock.Unlock()
+		otherExecutions++
+		return nil
+	}
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, handler, store, pkg.WithNamespaceHandler(namespace, namespaceHandler), pkg.WithNamespaceConcurrency(namespace, 1))
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		definition, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 0)
+		require.NoError(t, err)
+		definition.Namespace = namespace
+		if i == 3 {
+			definition.Namespace = otherNamespace
+		}
+		err = scheduler.UpsertTaskDefinition(definition)
+		require.NoError(t, err)
+	}
+	count, err := scheduler.CountNamespaceTaskDefinitions(namespace)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+	definitions, err := scheduler.ListNamespaceTaskDefinitions(namespace, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, definitions, 3)
+	go scheduler.Run()
+	defer scheduler.Stop()
+	time.Sleep(3 * time.Second)
+	lock.Lock()
+	// routed to the namespace's handler, one at a time
+	require.Greater(t, namespaceExecutions, 0)
+	require.Greater(t, otherExecutions, 0)
+	require.Equal(t, 1, maxRunning)
+	lock.Unlock()
+	err = scheduler.PauseNamespace(namespace)
+	require.NoError(t, err)
+	definitions, err = scheduler.ListNamespaceTaskDefinitions(namespace, 0, 10)
+	require.NoError(t, err)
+	for _, definition := range definitions {
+		require.True(t, definition.Paused)
+	}
+	err = scheduler.ResumeNamespace(namespace, pkg.ResumeSkipMissed)
+	require.NoError(t, err)
+	definitions, err = scheduler.ListNamespaceTaskDefinitions(namespace, 0, 10)
+	require.NoError(t, err)
+	for _, definition := range definitions {
+		require.False(t, definition.Paused)
+	}
+	err = scheduler.DeleteNamespace(namespace)
+	require.NoError(t, err)
+	count, err = scheduler.CountNamespaceTaskDefinitions(namespace)
+	require.NoError(t, err)
+	require.Zero(t, count)
+	count, err = scheduler.CountNamespaceTaskDefinitions(otherNamespace)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+	err = scheduler.DeleteNamespace(otherNamespace)
+	require.NoError(t, err)
+}
+
+func TestTenants(t *testing.T, store pkg.StoreInterface) {
+	tenantId := fmt.Sprintf("tenant-%s", uuid.New())
+	otherTenantId := fmt.Sprintf("tenant-%s", uuid.New())
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, func(task pkg.TaskInstance) error { return nil }, store, pkg.WithTenantQuota(tenantId, pkg.TenantQuota{MaxTaskDefinitions: 2}))
+	require.NoError(t, err)
+	tenant, err := scheduler.ForTenant(tenantId)
+	require.NoError(t, err)
+	otherTenant, err := scheduler.ForTenant(otherTenantId)
+	require.NoError(t, err)
+	_, err = scheduler.ForTenant("")
+	require.Error(t, err)
+	definitions := []pkg.TaskDefinition{}
+	for i := 0; i < 2; i++ {
+		definition, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 0)
+		require.NoError(t, err)
+		err = tenant.UpsertTaskDefinition(definition)
+		require.NoError(t, err)
+		definitions = append(definitions, definition)
+	}
+	// over the quota
+	definition, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 0)
+	require.NoError(t, err)
+	err = tenant.UpsertTaskDefinition(definition)
+	require.True(t, errorx.IsOfType(err, pkg.QuotaExceeded))
+	// updating an existing task definition doesn't count against the quota
+	err = tenant.UpsertTaskDefinition(definitions[0])
+	require.NoError(t, err)
+	count, err := tenant.CountTaskDefinitions(pkg.TaskDefinitionFilter{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+	// the other tenant can't see or change the tenant's task definitions
+	listed, err := otherTenant.ListTaskDefinitions(0, 10, pkg.TaskDefinitionFilter{})
+	require.NoError(t, err)
+	require.Empty(t, listed)
+	_, err = otherTenant.GetTaskDefinition(definitions[0].Id)
+	require.True(t, errorx.IsNotFound(err))
+	err = otherTenant.UpsertTaskDefinition(definitions[0])
+	require.True(t, errorx.IsOfType(err, pkg.TenantMismatch))
+	// as does the unscoped scheduler, the store refuses to move a task definition to another tenant
+	moved := definitions[0]
+	moved.TenantId = otherTenantId
+	err = scheduler.UpsertTaskDefinition(moved)
+	require.True(t, errorx.IsOfType(err, pkg.TenantMismatch))
+	err = otherTenant.PauseTaskDefinition(definitions[0].Id)
+	require.True(t, errorx.IsNotFound(err))
+	_, err = otherTenant.TriggerNow(definitions[0].Id, pkg.TriggerNowOptions{})
+	require.True(t, errorx.IsNotFound(err))
+	err = otherTenant.DeleteTaskDefinition(definitions[0].Id)
+	require.NoError(t, err)
+	fetched, err := tenant.GetTaskDefinition(definitions[0].Id)
+	require.NoError(t, err)
+	require.Equal(t, tenantId, fetched.TenantId)
+	err = otherTenant.DeleteTaskDefinitions(pkg.TaskDefinitionFilter{})
+	require.NoError(t, err)
+	count, err = tenant.CountTaskDefinitions(pkg.TaskDefinitionFilter{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+	_, err = otherTenant.ListTaskInstances(definitions[0].Id, time.Now().Add(-time.Minute), time.Now())
+	require.True(t, errorx.IsNotFound(err))
+	_, err = otherTenant.ListWorkflowRuns(definitions[0].Id, 0, 10)
+	require.True(t, errorx.IsNotFound(err))
+	_, err = otherTenant.Backfill(definitions[0].Id, time.Now().Add(-time.Minute), time.Now(), pkg.BackfillOptions{})
+	require.True(t, errorx.IsNotFound(err))
+	// the tenant can, task instances carry the tenant id
+	taskInstance, err := tenant.TriggerNow(definitions[0].Id, pkg.TriggerNowOptions{})
+	require.NoError(t, err)
+	require.Equal(t, tenantId, taskInstance.TenantId)
+	namespace := fmt.Sprintf("team-%s", uuid.New())
+	definitions[1].Namespace = namespace
+	err = tenant.UpsertTaskDefinition(definitions[1])
+	require.NoError(t, err)
+	err = otherTenant.PauseNamespace(namespace)
+	require.NoError(t, err)
+	err = otherTenant.DeleteNamespace(namespace)
+	require.NoError(t, err)
+	err = tenant.PauseNamespace(namespace)
+	require.NoError(t, err)
+	fetched, err = tenant.GetTaskDefinition(definitions[1].Id)
+	require.NoError(t, err)
+	require.True(t, fetched.Paused)
+	err = tenant.ResumeNamespace(namespace, pkg.ResumeSkipMissed)
+	require.NoError(t, err)
+	fetched, err = tenant.GetTaskDefinition(definitions[1].Id)
+	require.NoError(t, err)
+	require.False(t, fetched.Paused)
+	err = tenant.PauseTaskDefinition(definitions[0].Id)
+	require.NoError(t, err)
+	paused := true
+	count, err = tenant.CountTaskDefinitions(pkg.TaskDefinitionFilter{Paused: &paused})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+	err = tenant.DeleteTaskDefinition(definitions[0].Id)
+	require.NoError(t, err)
+	_, err = tenant.GetTaskDefinition(definitions[0].Id)
+	require.True(t, errorx.IsNotFound(err))
+	err = tenant.DeleteTaskDefinitions(pkg.TaskDefinitionFilter{})
+	require.NoError(t, err)
+	count, err = tenant.CountTaskDefinitions(pkg.TaskDefinitionFilter{})
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestTaskDefinitionKeys(t *testing.T, store pkg.StoreInterface) {
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, func(task pkg.TaskInstance) error { return nil }, store)
+	require.NoError(t, err)
+	key := fmt.Sprintf("reminder-%s", uuid.New())
+	definition, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 0)
+	require.NoError(t, err)
+	definition.Key = key
+	stored, err := scheduler.UpsertTaskDefinitionByKey(definition, pkg.KeyConflictReplace)
+	require.NoError(t, err)
+	require.Equal(t, definition.Id, stored.Id)
+	// a retry with a fresh id replaces the task definition instead of creating a duplicate
+	retry, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 0)
+	require.NoError(t, err)
+	retry.Key = key
+	stored, err = scheduler.UpsertTaskDefinitionByKey(retry, pkg.KeyConflictReplace)
+	require.NoError(t, err)
+	require.Equal(t, definition.Id, stored.Id)
+	fetched, err := scheduler.GetTaskDefinitionByKey(key)
+	require.NoError(t, err)
+	require.Equal(t, definition.Id, fetched.Id)
+	require.Equal(t, retry.Metadata, fetched.Metadata)
+	// the returned task definition is the stored one, so it can be updated with its version
+	require.Equal(t, int64(2), stored.Version)
+	require.Equal(t, fetched.Version, stored.Version)
+	require.Equal(t, fetched.NextFireTime.Unix(), stored.NextFireTime.Unix())
+	stored.Metadata = TestMetaData{Message: "updated"}
+	require.NoError(t, scheduler.UpsertTaskDefinition(stored))
+	// keep returns the existing task definition
+	kept, err := scheduler.UpsertTaskDefinitionByKey(definition, pkg.KeyConflictKeep)
+	require.NoError(t, err)
+	require.Equal(t, retry.Metadata, kept.Metadata)
+	_, err = scheduler.UpsertTaskDefinitionByKey(definition, pkg.KeyConflictError)
+	require.True(t, errorx.IsOfType(err, pkg.DuplicateKey))
+	// the store rejects a second task definition with the key
+	err = scheduler.UpsertTaskDefinition(retry)
+	require.True(t, errorx.IsOfType(err, pkg.DuplicateKey))
+	// keys are unique per tenant
+	tenant, err := scheduler.ForTenant(fmt.Sprintf("tenant-%s", uuid.New()))
+	require.NoError(t, err)
+	tenantDefinition, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 0)
+	require.NoError(t, err)
+	tenantDefinition.Key = key
+	stored, err = tenant.UpsertTaskDefinitionByKey(tenantDefinition, pkg.KeyConflictError)
+	require.NoError(t, err)
+	require.Equal(t, tenantDefinition.Id, stored.Id)
+	err = scheduler.DeleteTaskDefinitionByKey(key)
+	require.NoError(t, err)
+	_, err = scheduler.GetTaskDefinitionByKey(key)
+	require.True(t, errorx.IsNotFound(err))
+	fetched, err = tenant.GetTaskDefinitionByKey(key)
+	require.NoError(t, err)
+	require.Equal(t, tenantDefinition.Id, fetched.Id)
+	err = tenant.DeleteTaskDefinitionByKey(key)
+	require.NoError(t, err)
+	_, err = tenant.GetTaskDefinitionByKey(key)
+	require.True(t, errorx.IsNotFound(err))
+}
+
+func TestScheduleVersionConflicts(t *testing.T, store pkg.StoreInterface) {
+	executions := map[time.Time]int{}
+	lock := new(sync.Mutex)
+	handler := func(task pkg.TaskInstance) error {
+		lock.Lock()
+		executions[task.FireTime.Truncate(time.Second)]++
+		lock.Unlock()
+		return nil
+	}
+	// clean up less often than the test runs, so the task instances are still there afterwards
+	conflicting := &versionBumpingStore{StoreInterface: store, calls: map[uuid.UUID]int{}}
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Minute, handler, conflicting)
+	require.NoError(t, err)
+	definition, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 1*time.Minute)
+	require.NoError(t, err)
+	definition.MisfirePolicy = pkg.MisfirePolicyFireAll
+	err = scheduler.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	go scheduler.Run()
+	time.Sleep(6 * time.Second)
+	scheduler.Stop()
+	time.Sleep(1 * time.Second)
+	// every other update conflicts, the task instances of conflicting updates aren't created and fire again later
+	taskInstances, err := store.ListTaskInstancesForTaskDefinition(definition.Id, time.Now().Add(-1*time.Minute), time.Now().Add(1*time.Minute))
+	require.NoError(t, err)
+	require.NotEmpty(t, taskInstances)
+	fireTimes := map[time.Time]bool{}
+	for _, taskInstance := range taskInstances {
+		fireTime := taskInstance.FireTime.Truncate(time.Second)
+		require.False(t, fireTimes[fireTime], "more than one task instance for %s", fireTime)
+		fireTimes[fireTime] = true
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	for fireTime, count := range executions {
+		require.Equal(t, 1, count, "fire time %s ran %d times", fireTime, count)
+	}
+	fetched, err := store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.Equal(t, len(taskInstances), fetched.Occurrences)
+}
+
+func TestOptimisticConcurrency(t *testing.T, store pkg.StoreInterface) {
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Second, func(task pkg.TaskInstance) error { return nil }, store)
+	require.NoError(t, err)
+	definition, err := generateRandomTaskWithCronTrigger(oncePerSecondCron, 0)
+	require.NoError(t, err)
+	err = scheduler.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	fetched, err := store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), fetched.Version)
+	// an update with the current version succeeds and increments it
+	fetched.Metadata = TestMetaData{Message: "first edit"}
+	err = scheduler.UpsertTaskDefinition(fetched)
+	require.NoError(t, err)
+	// an update with a stale version is a conflict
+	stale := fetched
+	stale.Metadata = TestMetaData{Message: "stale edit"}
+	err = scheduler.UpsertTaskDefinition(stale)
+	require.True(t, errorx.IsOfType(err, pkg.VersionConflict))
+	current, err := store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), current.Version)
+	// the schedule loop's update is conditional on the version too, and creates no task instances when it conflicts
+	staleTaskInstance := generateRandomTaskInstance(stale)
+	staleTaskInstanceId := uuid.New()
+	staleTaskInstance.Id = &staleTaskInstanceId
+	created, err := store.ScheduleTaskDefinition(stale, []pkg.TaskInstance{staleTaskInstance})
+	require.True(t, errorx.IsOfType(err, pkg.VersionConflict))
+	require.Empty(t, created)
+	_, err = store.GetTaskInstance(staleTaskInstance.Id)
+	require.Error(t, err)
+	// and only changes the fields it owns
+	current.Occurrences = 5
+	current.Metadata = TestMetaData{Message: "not saved"}
+	_, err = store.ScheduleTaskDefinition(current, nil)
+	require.NoError(t, err)
+	updated, err := store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), updated.Version)
+	require.Equal(t, 5, updated.Occurrences)
+	metadata, err := json.Marshal(updated.Metadata)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Message":"first edit"}`, string(metadata))
+	// a version of 0 updates the stored version, keeping the fields the scheduler owns
+	definition.Metadata = TestMetaData{Message: "overwrite"}
+	err = scheduler.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	updated, err = store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), updated.Version)
+	require.Equal(t, 5, updated.Occurrences)
+	require.Equal(t, current.NextFireTime.Unix(), updated.NextFireTime.Unix())
+	err = scheduler.DeleteTaskDefinition(definition.Id)
+	require.NoError(t, err)
+}
+
+func TestTypedPayloads(t *testing.T, store pkg.StoreInterface) {
+	lock := new(sync.Mutex)
+	received := []reminderPayload{}
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Minute, func(task pkg.TaskInstance) error { return nil }, store)
+	require.NoError(t, err)
+	pkg.RegisterTypedHandler(scheduler, func(taskInstance pkg.TaskInstance, payload reminderPayload) error {
+		lock.Lock()
+		defer lock.Unlock()
+		received = append(received, payload)
+		return nil
+	})
+	expected := reminderPayload{UserId: uuid.New().String(), Message: "drink water"}
+	definition, err := pkg.NewTypedTask(generateRandomTaskWithExecuteOnceTrigger(time.Now().Add(1*time.Second), 0), expected).ToTaskDefinition()
+	require.NoError(t, err)
+	err = scheduler.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	// a payload that can't be decoded fails permanently, without using up its attempts
+	corrupt := generateRandomTaskWithExecuteOnceTrigger(time.Now().Add(1*time.Second), 0)
+	corrupt.PayloadType = pkg.PayloadTypeName[reminderPayload]()
+	corrupt.Payload = []byte("not json")
+	corrupt.MaxAttempts = 5
+	err = scheduler.UpsertTaskDefinition(corrupt)
+	require.NoError(t, err)
+	fetched, err := store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	actual, err := pkg.DecodePayload[reminderPayload](pkg.TaskInstance{TaskDefinition: fetched})
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+	go scheduler.Run()
+	defer scheduler.Stop()
+	time.Sleep(4 * time.Second)
+	lock.Lock()
+	require.Equal(t, []reminderPayload{expected}, received)
+	lock.Unlock()
+	taskInstances, err := store.ListTaskInstancesForTaskDefinition(corrupt.Id, time.Now().Add(-1*time.Minute), time.Now())
+	require.NoError(t, err)
+	require.Len(t, taskInstances, 1)
+	require.NotNil(t, taskInstances[0].FailedAt)
+	require.Equal(t, 1, taskInstances[0].Attempts)
+}
+
+func TestPayloadEncryption(t *testing.T, store pkg.StoreInterface) {
+	oldProvider, err := pkg.NewStaticKeyProvider("old", map[string][]byte{"old": oldKey})
+	require.NoError(t, err)
+	lock := new(sync.Mutex)
+	received := []reminderPayload{}
+	scheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Minute, func(task pkg.TaskInstance) error { return nil }, store, pkg.WithEncryption(oldProvider))
+	require.NoError(t, err)
+	pkg.RegisterTypedHandler(scheduler, func(taskInstance pkg.TaskInstance, payload reminderPayload) error {
+		lock.Lock()
+		defer lock.Unlock()
+		received = append(received, payload)
+		return nil
+	})
+	expected := reminderPayload{UserId: uuid.New().String(), Message: "call +15555550100"}
+	definition, err := pkg.NewTypedTask(generateRandomTaskWithExecuteOnceTrigger(time.Now().Add(1*time.Second), 0), expected).ToTaskDefinition()
+	require.NoError(t, err)
+	definition.Metadata = map[string]interface{}{"user_id": expected.UserId}
+	err = scheduler.UpsertTaskDefinition(definition)
+	require.NoError(t, err)
+	// the payload is stored encrypted, metadata isn't
+	stored, err := store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.NotNil(t, stored.PayloadEnvelope)
+	require.NotContains(t, string(stored.Payload), "+15555550100")
+	listed, err := scheduler.ListTaskDefinitions(0, 10, map[string]interface{}{"user_id": expected.UserId})
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	// the handler gets it decrypted
+	go scheduler.Run()
+	time.Sleep(4 * time.Second)
+	scheduler.Stop()
+	lock.Lock()
+	require.Equal(t, []reminderPayload{expected}, received)
+	lock.Unlock()
+	// rotate to a new key and rewrap, the old key isn't needed anymore afterwards
+	rotatedProvider, err := pkg.NewStaticKeyProvider("new", map[string][]byte{"old": oldKey, "new": newKey})
+	require.NoError(t, err)
+	rotatedScheduler, err := pkg.NewScheduler(1*time.Second, 1*time.Second, 1*time.Minute, func(task pkg.TaskInstance) error { return nil }, store, pkg.WithEncryption(rotatedProvider))
+	require.NoError(t, err)
+	rewrapped, err := rotatedScheduler.RewrapPayloadKeys()
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, rewrapped, 1)
+	stored, err = store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.Equal(t, "new", stored.PayloadEnvelope.KeyId)
+	newProvider, err := pkg.NewStaticKeyProvider("new", map[string][]byte{"new": newKey})
+	require.NoError(t, err)
+	decrypted, err := pkg.DecryptPayload(newProvider, stored)
+	require.NoError(t, err)
+	actual, err := pkg.DecodePayload[reminderPayload](pkg.TaskInstance{TaskDefinition: decrypted})
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+	// editing the payload of a task definition read from the store encrypts the new payload
+	edited := reminderPayload{UserId: expected.UserId, Message: "call +15555550199"}
+	stored.Payload, err = json.Marshal(edited)
+	require.NoError(t, err)
+	err = rotatedScheduler.UpsertTaskDefinition(stored)
+	require.NoError(t, err)
+	stored, err = store.GetTaskDefinition(definition.Id)
+	require.NoError(t, err)
+	require.NotContains(t, string(stored.Payload), "+15555550199")
+	decrypted, err = pkg.DecryptPayload(newProvider, stored)
+	require.NoError(t, err)
+	actual, err = pkg.DecodePayload[reminderPayload](pkg.TaskInstance{TaskDefinition: decrypted})
+	require.NoError(t, err)
+	require.Equal(t, edited, actual)
+}
+
 func assertTaskEquality(t *testing.T, expected, actual pkg.TaskDefinition) {
 	require.Equal(t, expected.Id, actual.Id)
 	require.Equal(t, expected.ExpireAfter, actual.ExpireAfter)
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestTriggerRegistryRoundTrip(t *testing.T) {
+	trigger, err := pkg.NewRegisteredTrigger(everyOtherSecondTrigger{Offset: 1})
+	require.NoError(t, err)
+	id := uuid.New()
+	definition := pkg.TaskDefinition{Id: &id, Metadata: TestMetaData{Message: "hi"}, Trigger: trigger}
+	bytes, err := definition.AsBytes()
+	require.NoError(t, err)
+	require.Contains(t, string(bytes), `"type":"every_other_second"`)
+	loaded, err := pkg.TaskFromBytes(bytes)
+	require.NoError(t, err)
+	require.Equal(t, everyOtherSecondTrigger{Offset: 1}, loaded.GetTrigger())
+	from := time.Unix(1000, 0)
+	require.Equal(t, time.Unix(1001, 0), *loaded.GetFireTimeFrom(from))
+}
+
+func TestTriggerRegistryRejectsUnregisteredTriggers(t *testing.T) {
+	_, err := pkg.NewRegisteredTrigger(&everyOtherSecondTrigger{})
+	require.Error(t, err)
+	// a name can't be reused for a different type
+	err = pkg.RegisterTrigger("every_other_second", &everyOtherSecondTrigger{}, nil, nil)
+	require.Error(t, err)
+	_, err = pkg.TaskFromBytes([]byte(`{"trigger":{"type":"unknown","data":"e30="}}`))
+	require.Error(t, err)
+}
+
+func TestTaskFromBytesRebuildsCronTrigger(t *testing.T) {
+	definition, err := generateRandomTaskWithCronTrigger("0 0 9 * * * *", time.Minute)
+	require.NoError(t, err)
+	bytes, err := definition.AsBytes()
+	require.NoError(t, err)
+	loaded, err := pkg.TaskFromBytes(bytes)
+	require.NoError(t, err)
+	from := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC), loaded.GetFireTimeFrom(from).UTC())
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type namedPayload struct {
+	Value int `json:"value"`
+}
+
+func (namedPayload) PayloadType() string {
+	return "named"
+}
+
+func TestPayloadTypeName(t *testing.T) {
+	require.Equal(t, "github.com/catalystsquad/go-scheduler/test.reminderPayload", pkg.PayloadTypeName[reminderPayload]())
+	require.Equal(t, "named", pkg.PayloadTypeName[namedPayload]())
+}
+
+func TestTypedTaskPayloadRoundTrip(t *testing.T) {
+	expected := reminderPayload{UserId: "user-1", Message: "drink water"}
+	definition, err := pkg.NewTypedTask(pkg.TaskDefinition{Metadata: map[string]interface{}{"user_id": "user-1"}}, expected).ToTaskDefinition()
+	require.NoError(t, err)
+	require.Equal(t, pkg.PayloadTypeName[reminderPayload](), definition.PayloadType)
+	// the payload survives the task definition being marshalled, like it is by the store
+	bytes, err := json.Marshal(definition)
+	require.NoError(t, err)
+	definition, err = pkg.TaskFromBytes(bytes)
+	require.NoError(t, err)
+	actual, err := pkg.DecodePayload[reminderPayload](pkg.TaskInstance{TaskDefinition: definition})
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+	// decoding into the wrong type fails
+	_, err = pkg.DecodePayload[namedPayload](pkg.TaskInstance{TaskDefinition: definition})
+	require.Error(t, err)
+}
+
+func TestDecodePayloadFailsOnCorruptPayload(t *testing.T) {
+	definition := pkg.TaskDefinition{PayloadType: pkg.PayloadTypeName[reminderPayload](), Payload: []byte("not json")}
+	_, err := pkg.DecodePayload[reminderPayload](pkg.TaskInstance{TaskDefinition: definition})
+	require.Error(t, err)
+}
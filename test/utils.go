@@ -1,9 +1,11 @@
 package test
 
 import (
+	"bytes"
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/catalystsquad/go-scheduler/pkg"
 	"github.com/google/uuid"
+	"sync"
 	"time"
 )
 
@@ -19,3 +21,63 @@ func GenerateExecuteOnceTask(fireAt *time.Time) pkg.TaskDefinition {
 		ExecuteOnceTrigger: trigger,
 	}
 }
+
+// everyOtherSecondTrigger is a custom trigger for testing the trigger registry
+type everyOtherSecondTrigger struct {
+	Offset int `json:"offset"`
+}
+
+func (t everyOtherSecondTrigger) GetFireTime(from time.Time) *time.Time {
+	fireTime := from.Truncate(time.Second).Add(time.Second)
+	if fireTime.Unix()%2 != int64(t.Offset) {
+		fireTime = fireTime.Add(time.Second)
+	}
+	return &fireTime
+}
+
+func (t everyOtherSecondTrigger) IsRecurring() bool {
+	return true
+}
+
+func init() {
+	err := pkg.RegisterTrigger("every_other_second", everyOtherSecondTrigger{}, nil, nil)
+	if err != nil {
+		panic(err)
+	}
+}
+
+type reminderPayload struct {
+	UserId  string `json:"user_id"`
+	Message string `json:"message"`
+}
+
+var (
+	oldKey = bytes.Repeat([]byte{1}, 32)
+	newKey = bytes.Repeat([]byte{2}, 32)
+)
+
+// versionBumpingStore changes the version of a task definition right before every other update of its schedule, as if
+// it was edited while it was being scheduled
+type versionBumpingStore struct {
+	pkg.StoreInterface
+	lock  sync.Mutex
+	calls map[uuid.UUID]int
+}
+
+func (s *versionBumpingStore) ScheduleTaskDefinition(definition pkg.TaskDefinition, taskInstances []pkg.TaskInstance) ([]pkg.TaskInstance, error) {
+	s.lock.Lock()
+	s.calls[*definition.Id]++
+	bump := s.calls[*definition.Id]%2 == 1
+	s.lock.Unlock()
+	if bump {
+		stored, err := s.StoreInterface.GetTaskDefinition(definition.Id)
+		if err != nil {
+			return nil, err
+		}
+		err = s.StoreInterface.RescheduleTaskDefinition(definition.Id, stored.NextFireTime)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s.StoreInterface.ScheduleTaskDefinition(definition, taskInstances)
+}
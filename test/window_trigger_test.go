@@ -0,0 +1,40 @@
+package test
+
+import (
+	"github.com/catalystsquad/go-scheduler/pkg"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestWindowTrigger(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+	// sometime between 1am and 5am
+	trigger, err := pkg.NewWindowTrigger("0 0 1 * * * *", 4*time.Hour, 15*time.Minute, chicago)
+	require.NoError(t, err)
+	require.True(t, trigger.IsRecurring())
+	window, slot := trigger.GetWindow()
+	require.Equal(t, 4*time.Hour, window)
+	require.Equal(t, 15*time.Minute, slot)
+	// fire times are the start of the window
+	from := time.Date(2026, 10, 19, 12, 0, 0, 0, chicago)
+	require.True(t, time.Date(2026, 10, 20, 1, 0, 0, 0, chicago).Equal(*trigger.GetFireTime(from)))
+	// survives a round trip through json
+	id := uuid.New()
+	bytes, err := pkg.TaskDefinition{Id: &id, Metadata: TestMetaData{}, WindowTrigger: trigger}.AsBytes()
+	require.NoError(t, err)
+	loaded, err := pkg.TaskFromBytes(bytes)
+	require.NoError(t, err)
+	require.True(t, time.Date(2026, 10, 20, 1, 0, 0, 0, chicago).Equal(*loaded.GetFireTimeFrom(from)))
+	// invalid windows are rejected
+	_, err = pkg.NewWindowTrigger("0 0 1 * * * *", 0, 0, nil)
+	require.Error(t, err)
+	_, err = pkg.NewWindowTrigger("0 0 1 * * * *", 1*time.Hour, 2*time.Hour, nil)
+	require.Error(t, err)
+	_, err = pkg.NewWindowTrigger("0 0 1 * * * *", 24*time.Hour, 1*time.Second, nil)
+	require.Error(t, err)
+	_, err = pkg.NewWindowTrigger("not cron", 1*time.Hour, 0, nil)
+	require.Error(t, err)
+}